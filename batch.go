@@ -2,8 +2,12 @@ package goroutiner
 
 import (
 	"context"
-	"golang.org/x/sync/errgroup"
+	"fmt"
+	"golang.org/x/sync/semaphore"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // ---------------------------------------------------------------------------------------------------------------------
@@ -14,22 +18,65 @@ import (
 //
 // Not thread-safe, as there is no practical need to make it thread‑safe.
 type Batch struct {
+	id               uint64
 	ctx              context.Context
 	mws              []Middleware
 	goroutineConfigs []*goroutineConfig
+	limit            int
+	propagatePanics  bool
+	timeout          time.Duration
+	deadline         time.Time
+}
+
+// SequentialMode defines Batch.Sequential behaviour after a goroutine fails.
+type SequentialMode int
+
+const (
+	// SequentialStopOnError -- the rest goroutines are not started, ErrSkipped is used as their results.
+	SequentialStopOnError SequentialMode = iota
+	// SequentialRunAll -- the rest goroutines are executed anyway.
+	SequentialRunAll
+)
+
+// ErrorBudgetConfig configures Batch.ErrorBudget.
+// At least one of the limits (MaxErrors or MaxErrorRate) must be set.
+type ErrorBudgetConfig struct {
+	// MaxErrors is the number of tolerated errors: the budget is exhausted by the next one.
+	// Zero disables the limit.
+	MaxErrors int
+
+	// MaxErrorRate is the tolerated rate of errors among completed goroutines:
+	// the budget is exhausted, once the rate exceeds it.
+	// Must be within (0, 1). Zero disables the limit.
+	MaxErrorRate float64
+	// MinCompleted is the minimum number of completed goroutines to apply MaxErrorRate
+	// (otherwise, e.g. the first error would exhaust any rate). Required, if MaxErrorRate is set.
+	MinCompleted int
 }
 
 type goroutineConfig struct {
-	fn  Goroutine
-	mws []Middleware
+	fn   Goroutine
+	mws  []Middleware
+	name string
+	deps []string
+	// options -- see GoroutineOption.
+	labels     map[string]string
+	timeout    time.Duration
+	retry      Middleware
+	optional   bool
+	startDelay time.Duration
 }
 
 // ---------------------------------------------------------------------------------------------------------------------
 // Create
 // ---------------------------------------------------------------------------------------------------------------------
 
+// lastBatchID is used to generate unique Batch IDs.
+var lastBatchID uint64
+
 func newBatch(ctx context.Context, mws []Middleware) *Batch {
 	return &Batch{
+		id:               atomic.AddUint64(&lastBatchID, 1),
 		ctx:              ctx,
 		mws:              mws,
 		goroutineConfigs: make([]*goroutineConfig, 0),
@@ -65,6 +112,69 @@ func (b *Batch) Add(fn Goroutine, mws ...Middleware) *Batch {
 	return b
 }
 
+// AddWith adds a new goroutine to the Batch configured with options (name, labels, timeout, etc.):
+//
+//	b.AddWith(fn, goroutiner.WithName("fetch"), goroutiner.WithTimeout(time.Second), goroutiner.WithOptional())
+//
+// Options are available for middleware via GoroutineInfo.
+//
+// Panics if:
+//   - `fn` is nil
+//   - `opts` contains nil
+//   - the name given via WithName is already used in the Batch
+func (b *Batch) AddWith(fn Goroutine, opts ...GoroutineOption) *Batch {
+	if fn == nil {
+		panic("`fn` must not be `nil`")
+	}
+
+	cfg := &goroutineConfig{fn: fn}
+
+	for _, opt := range opts {
+		if opt == nil {
+			panic("`opts` must contain no `nil` elements")
+		}
+
+		opt(cfg)
+	}
+
+	if cfg.name != "" {
+		b.checkNameIsUnused(cfg.name)
+	}
+
+	b.goroutineConfigs = append(b.goroutineConfigs, cfg)
+
+	return b
+}
+
+// checkNameIsUnused panics, if the `name` is already used by any added goroutine.
+func (b *Batch) checkNameIsUnused(name string) {
+	for _, cfg := range b.goroutineConfigs {
+		if cfg.name == name {
+			panic(fmt.Sprintf("`name` %q is already used", name))
+		}
+	}
+}
+
+// AddOptional is the same as Batch.Add, but the goroutine is optional -- see WithOptional.
+//
+// Panics if:
+//   - `fn` is nil
+//   - `mws` contains nil
+func (b *Batch) AddOptional(fn Goroutine, mws ...Middleware) *Batch {
+	return b.AddWith(fn, WithOptional(), WithMiddleware(mws...))
+}
+
+// AddNamed is the same as Batch.Add, but the goroutine gets the `name` (see GoroutineInfo),
+// which also can be used as a dependency in Batch.AddNode.
+//
+// Panics if:
+//   - `name` is empty or is already used in the Batch
+//   - `fn` is nil
+//   - `mws` contains nil
+func (b *Batch) AddNamed(name string, fn Goroutine, mws ...Middleware) *Batch {
+	return b.AddNode(name, nil, fn, mws...)
+}
+
 // AddRange adds `n` goroutines using the `fnProvide`.
 // If a panic occurs, none of the provided goroutines are added.
 //
@@ -111,10 +221,78 @@ func (b *Batch) AddRange(n int, fnProvide func(i int) (Goroutine, []Middleware))
 	return b
 }
 
+// Limit sets the maximum number of added goroutines running at the same time.
+// Other goroutines are queued and started in the adding order as soon as running ones are completed.
+// If the context is done while a goroutine is queued, the goroutine is not started at all,
+// and the context error is used as its result.
+// Zero means no limit (default).
+//
+// Applied to all execution strategies.
+//
+// Panics if `n` < 0.
+func (b *Batch) Limit(n int) *Batch {
+	if n < 0 {
+		panic("`n` must not be negative")
+	}
+
+	b.limit = n
+
+	return b
+}
+
+// Timeout sets the maximum duration of each execution of the Batch.
+// The context passed to goroutines is cancelled once the timeout is reached.
+// Zero means no timeout (default).
+//
+// If Batch.Deadline is set too, the earliest one is applied.
+//
+// Panics if `d` < 0.
+func (b *Batch) Timeout(d time.Duration) *Batch {
+	if d < 0 {
+		panic("`d` must not be negative")
+	}
+
+	b.timeout = d
+
+	return b
+}
+
+// Deadline sets the time, when the context passed to goroutines is cancelled.
+// Zero time means no deadline (default).
+//
+// If Batch.Timeout is set too, the earliest one is applied.
+func (b *Batch) Deadline(t time.Time) *Batch {
+	b.deadline = t
+
+	return b
+}
+
+// PropagatePanics enables propagation of goroutine panics to the caller of execution strategies,
+// which await goroutines completion (i.e. all, except Async ones).
+//
+// Panics in the goroutines are recovered (after all middleware, so MwPanicToError etc. still work)
+// and treated as errors by the execution strategy (e.g. Wait lets other goroutines complete,
+// while CancelOnError cancels them).
+// Once all goroutines are completed, the calling goroutine panics with *GoroutinePanic value
+// of the first recovered panic.
+func (b *Batch) PropagatePanics() *Batch {
+	b.propagatePanics = true
+
+	return b
+}
+
 // Executing
 // ---------------------------------------------------------------------------------------------------------------------
 
-func (b *Batch) prepareGoroutines() []Goroutine {
+// prepareGoroutines applies middleware to goroutines and passes GoroutineInfo with the `strategy` name
+// to each of them via the context.
+func (b *Batch) prepareGoroutines(strategy string) []Goroutine {
+	return b.prepareGoroutinesAs(strategy, b.id, nil)
+}
+
+// prepareGoroutinesAs is the same as prepareGoroutines, but GoroutineInfo is given the `batchID`
+// and `indexes` of goroutines (nil means the adding order) -- for goroutines executed on behalf of another batch.
+func (b *Batch) prepareGoroutinesAs(strategy string, batchID uint64, indexes []int) []Goroutine {
 	goroutines := make([]Goroutine, len(b.goroutineConfigs))
 
 	if len(goroutines) == 0 {
@@ -122,7 +300,7 @@ func (b *Batch) prepareGoroutines() []Goroutine {
 	}
 
 	for i, cfg := range b.goroutineConfigs {
-		goroutines[i] = cfg.fn
+		goroutines[i] = cfg.fnWithOptions()
 
 		for j := len(cfg.mws) - 1; j >= 0; j-- {
 			goroutines[i] = cfg.mws[j](goroutines[i])
@@ -131,11 +309,126 @@ func (b *Batch) prepareGoroutines() []Goroutine {
 		for j := len(b.mws) - 1; j >= 0; j-- {
 			goroutines[i] = b.mws[j](goroutines[i])
 		}
+
+		index := i
+		if indexes != nil {
+			index = indexes[i]
+		}
+
+		goroutines[i] = cfg.delayStart(withInfo(goroutines[i], GoroutineInfo{
+			Index:      index,
+			Name:       cfg.name,
+			BatchID:    batchID,
+			Strategy:   strategy,
+			Labels:     cfg.labels,
+			Timeout:    cfg.timeout,
+			Optional:   cfg.optional,
+			StartDelay: cfg.startDelay,
+		}))
 	}
 
 	return goroutines
 }
 
+// executionContext returns the Batch context with applied timeout and deadline.
+// Returned `cancel` must be called once the execution is completed.
+func (b *Batch) executionContext() (context.Context, context.CancelFunc) {
+	deadline := b.deadline
+
+	if b.timeout > 0 {
+		if timeoutDeadline := time.Now().Add(b.timeout); deadline.IsZero() || timeoutDeadline.Before(deadline) {
+			deadline = timeoutDeadline
+		}
+	}
+
+	if deadline.IsZero() {
+		return context.WithCancel(b.ctx)
+	}
+
+	return context.WithDeadline(b.ctx, deadline)
+}
+
+// catchPanics wraps goroutines `gs` to recover their panics, if the panic propagation is enabled.
+// Recovered panic is returned as the goroutine error.
+// Returned `fnRepanic` panics with the first recovered panic (if any) -- it must be called after the execution.
+func (b *Batch) catchPanics(gs []Goroutine) (wrapped []Goroutine, fnRepanic func()) {
+	if !b.propagatePanics {
+		return gs, func() {}
+	}
+
+	var firstPanic *GoroutinePanic
+	panicOnce := new(sync.Once)
+
+	wrapped = make([]Goroutine, len(gs))
+
+	for i, g := range gs {
+		func(i int, g Goroutine) {
+			wrapped[i] = func(ctx context.Context) (rErr error) {
+				defer func() {
+					if pv := recover(); pv != nil {
+						gp := &GoroutinePanic{Index: i, Value: pv, Stack: debug.Stack()}
+						panicOnce.Do(func() {
+							firstPanic = gp
+						})
+						rErr = gp
+					}
+				}()
+
+				return g(ctx)
+			}
+		}(i, g)
+	}
+
+	return wrapped, func() {
+		if firstPanic != nil {
+			panic(firstPanic)
+		}
+	}
+}
+
+// newLimiter returns a semaphore for the concurrency limit, or nil if there is no limit.
+// Semaphore must be created for each execution, because the Batch can be executed several times.
+func (b *Batch) newLimiter() *semaphore.Weighted {
+	if b.limit == 0 {
+		return nil
+	}
+
+	return semaphore.NewWeighted(int64(b.limit))
+}
+
+// execute runs goroutines `gs` with the `ctx` respecting the concurrency limit
+// and passes the result of the `i`-th goroutine to `fnDone` (can be called concurrently).
+// Blocks until all started goroutines are completed.
+func (b *Batch) execute(ctx context.Context, gs []Goroutine, fnDone func(i int, err error)) {
+	sem := b.newLimiter()
+
+	wg := new(sync.WaitGroup)
+
+	for i, g := range gs {
+		if sem != nil {
+			if err := sem.Acquire(ctx, 1); err != nil {
+				// context is done -- queued goroutines must not be started.
+				for j := i; j < len(gs); j++ {
+					fnDone(j, err)
+				}
+				break
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, g Goroutine) {
+			defer wg.Done()
+			if sem != nil {
+				defer sem.Release(1)
+			}
+
+			fnDone(i, g(ctx))
+		}(i, g)
+	}
+
+	wg.Wait()
+}
+
 // Execution - Wait
 // ---------------------------------------------------------------------------------------------------------------------
 
@@ -143,47 +436,64 @@ func (b *Batch) prepareGoroutines() []Goroutine {
 // Returns a slice of errors: index `i` matches `i`-th added goroutine.
 //
 // Panics if no goroutines were added to the Batch.
+//
+// Panics with *GoroutinePanic if any goroutine panicked and Batch.PropagatePanics is enabled.
 func (b *Batch) Wait() []error {
-	gs := b.prepareGoroutines()
+	gs := b.prepareGoroutines("Wait")
 
-	type ChErr = struct {
-		I   int
-		Err error
-	}
+	return b.wait(gs)
+}
 
-	errCh := make(chan ChErr, len(gs))
+// WaitErr is the same as Batch.Wait, but returns a single *BatchError aggregating all errors
+// (nil, if all goroutines succeeded) -- see NewBatchError.
+// Names of goroutines (see Batch.AddNamed) are set to the BatchError too.
+func (b *Batch) WaitErr() error {
+	return b.namedBatchError(b.Wait())
+}
 
-	// wrapped into a function to be sure, that channel will be closed in any case after wg.Wait()
-	// so reading from the channel will not lock the goroutine after all elements are pulled.
-	func(errCh chan ChErr, gs []Goroutine, ctx context.Context) {
-		defer close(errCh)
+// namedBatchError returns NewBatchError for `errs` with names of goroutines.
+func (b *Batch) namedBatchError(errs []error) error {
+	err := NewBatchError(errs)
 
-		wg := new(sync.WaitGroup)
-		wg.Add(len(gs))
-		for i, g := range gs {
-			go func(i int, g func(context.Context) error) {
-				defer wg.Done()
+	if batchErr, ok := err.(*BatchError); ok {
+		batchErr.Names = make([]string, len(b.goroutineConfigs))
+		for i, cfg := range b.goroutineConfigs {
+			batchErr.Names[i] = cfg.name
+		}
+	}
 
-				err := g(ctx)
+	return err
+}
 
-				errCh <- ChErr{i, err}
-			}(i, g)
-		}
-		wg.Wait()
-	}(errCh, gs, b.ctx)
+func (b *Batch) wait(gs []Goroutine) []error {
+	errs, fnRepanic := b.waitDeferringPanics(gs)
 
-	errs := make([]error, len(gs))
-	for chErr := range errCh {
-		errs[chErr.I] = chErr.Err
-	}
+	fnRepanic()
 
 	return errs
 }
 
+// waitDeferringPanics is the same as wait, but returns `fnRepanic` (see catchPanics) instead of calling it.
+func (b *Batch) waitDeferringPanics(gs []Goroutine) (errs []error, fnRepanic func()) {
+	gs, fnRepanic = b.catchPanics(gs)
+
+	ctx, cancel := b.executionContext()
+	defer cancel()
+
+	// each goroutine writes only its own element, and all of them are completed after `execute`.
+	errs = make([]error, len(gs))
+	b.execute(ctx, gs, func(i int, err error) {
+		errs[i] = err
+	})
+
+	return errs, fnRepanic
+}
+
 // Execution - CancelOnError
 // ---------------------------------------------------------------------------------------------------------------------
 
-// CancelOnError executes all goroutines using errgroup.WithContext -- see https://pkg.go.dev/golang.org/x/sync/errgroup.
+// CancelOnError executes all goroutines in the same way as errgroup.WithContext does --
+// see https://pkg.go.dev/golang.org/x/sync/errgroup.
 //
 // "...
 // WithContext returns a new Group and an associated Context derived from ctx.
@@ -191,21 +501,311 @@ func (b *Batch) Wait() []error {
 // or the first time Wait returns, whichever occurs first.
 // ..."
 //
+// Returns the first non-nil error (if any).
+// Errors of optional goroutines (see WithOptional) neither cancel others, nor are returned.
+//
 // Panics if no goroutines were added to the Batch.
+// Panics with *GoroutinePanic if any goroutine panicked and Batch.PropagatePanics is enabled.
 func (b *Batch) CancelOnError() error {
-	gs := b.prepareGoroutines()
+	gs := b.prepareGoroutines("CancelOnError")
 
-	eg, egCtx := errgroup.WithContext(b.ctx)
+	return b.cancelOnError(gs, func(i int, err error) {})
+}
+
+// CancelOnErrorWithOptional is the same as Batch.CancelOnError, but also reports errors of optional goroutines
+// (see WithOptional and Batch.AddOptional), which never cancel others.
+//
+// Returns:
+//   - a slice of errors of optional goroutines: index `i` matches `i`-th added goroutine (nil for critical ones)
+//   - the first non-nil error of critical (i.e. not optional) goroutines
+func (b *Batch) CancelOnErrorWithOptional() (optionalErrs []error, err error) {
+	gs := b.prepareGoroutines("CancelOnError")
+
+	optionalErrs = make([]error, len(gs))
+
+	err = b.cancelOnError(gs, func(i int, err error) {
+		if b.goroutineConfigs[i].optional {
+			optionalErrs[i] = err
+		}
+	})
+
+	return optionalErrs, err
+}
 
-	for _, g := range gs {
-		func(g Goroutine) {
-			eg.Go(func() error {
-				return g(egCtx)
+func (b *Batch) cancelOnError(gs []Goroutine, fnDone func(i int, err error)) error {
+	gs, fnRepanic := b.catchPanics(gs)
+
+	ctx, cancel := b.executionContext()
+	defer cancel()
+
+	var firstErr error
+	errOnce := new(sync.Once)
+
+	b.execute(ctx, gs, func(i int, err error) {
+		fnDone(i, err)
+
+		if err != nil && !b.goroutineConfigs[i].optional {
+			errOnce.Do(func() {
+				firstErr = err
+				cancel()
 			})
-		}(g)
+		}
+	})
+
+	fnRepanic()
+
+	return firstErr
+}
+
+// Execution - FirstSuccess
+// ---------------------------------------------------------------------------------------------------------------------
+
+// FirstSuccess executes all goroutines and waits for the first successful one (i.e. returned nil error).
+// Once it happens, the context of other goroutines is cancelled, and they are awaited to be completed.
+//
+// Returns the index of the first successful goroutine and nil.
+// If all goroutines failed, returns -1 and a slice of errors: index `i` matches `i`-th added goroutine.
+//
+// Example use case: ask several replicas and take the first good answer.
+//
+// Panics if no goroutines were added to the Batch.
+// Panics with *GoroutinePanic if any goroutine panicked and Batch.PropagatePanics is enabled.
+func (b *Batch) FirstSuccess() (int, []error) {
+	gs := b.prepareGoroutines("FirstSuccess")
+	gs, fnRepanic := b.catchPanics(gs)
+
+	ctx, cancel := b.executionContext()
+	defer cancel()
+
+	winner := -1
+	winnerOnce := new(sync.Once)
+
+	errs := make([]error, len(gs))
+
+	b.execute(ctx, gs, func(i int, err error) {
+		errs[i] = err
+
+		if err == nil {
+			winnerOnce.Do(func() {
+				winner = i
+				cancel()
+			})
+		}
+	})
+
+	fnRepanic()
+
+	if winner >= 0 {
+		return winner, nil
 	}
 
-	return eg.Wait()
+	return -1, errs
+}
+
+// Execution - Quorum
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Quorum executes all goroutines and waits until at least `k` of them succeed (i.e. return nil error).
+// The context of other goroutines is cancelled as soon as the outcome is decided:
+// either `k` goroutines succeeded, or so many goroutines failed, that `k` successes are not possible anymore.
+// All goroutines are awaited to be completed.
+//
+// Returns:
+//   - indexes of succeeded goroutines in ascending order
+//   - a slice of errors: index `i` matches `i`-th added goroutine
+//   - whether the quorum is reached
+//
+// Example use case: replicated writes.
+//
+// Panics if:
+//   - `k` <= 0
+//   - `k` is greater than the number of added goroutines
+//   - no goroutines were added to the Batch
+//   - any goroutine panicked and Batch.PropagatePanics is enabled (with *GoroutinePanic)
+func (b *Batch) Quorum(k int) (succeeded []int, errs []error, ok bool) {
+	if k <= 0 {
+		panic("`k` must be greater than zero")
+	}
+
+	gs := b.prepareGoroutines("Quorum")
+
+	if k > len(gs) {
+		panic("`k` must not be greater than the number of added goroutines")
+	}
+
+	gs, fnRepanic := b.catchPanics(gs)
+
+	ctx, cancel := b.executionContext()
+	defer cancel()
+
+	mu := new(sync.Mutex)
+	successes, failures := 0, 0
+
+	errs = make([]error, len(gs))
+
+	b.execute(ctx, gs, func(i int, err error) {
+		errs[i] = err
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err == nil {
+			successes++
+		} else {
+			failures++
+		}
+
+		if successes >= k || failures > len(gs)-k {
+			cancel()
+		}
+	})
+
+	fnRepanic()
+
+	succeeded = make([]int, 0, successes)
+	for i, err := range errs {
+		if err == nil {
+			succeeded = append(succeeded, i)
+		}
+	}
+
+	return succeeded, errs, len(succeeded) >= k
+}
+
+// Execution - ErrorBudget
+// ---------------------------------------------------------------------------------------------------------------------
+
+// ErrorBudget executes all goroutines tolerating some errors (see ErrorBudgetConfig):
+// once the error budget is exhausted, the context of other goroutines is cancelled.
+// It is a middle ground between Batch.Wait (never cancels) and Batch.CancelOnError (cancels on the first error),
+// e.g. for large fan-outs via Batch.AddRange.
+// Errors of optional goroutines (see WithOptional) are not counted. All goroutines are awaited to be completed.
+//
+// Returns:
+//   - a slice of errors: index `i` matches `i`-th added goroutine
+//   - whether the budget is exhausted
+//
+// Panics if:
+//   - `cfg.MaxErrors` < 0
+//   - `cfg.MaxErrorRate` is not within [0, 1)
+//   - `cfg.MinCompleted` < 0
+//   - `cfg.MinCompleted` is zero, while `cfg.MaxErrorRate` is set
+//   - both `cfg.MaxErrors` and `cfg.MaxErrorRate` are zero
+//   - no goroutines were added to the Batch
+//   - any goroutine panicked and Batch.PropagatePanics is enabled (with *GoroutinePanic)
+func (b *Batch) ErrorBudget(cfg ErrorBudgetConfig) (errs []error, exhausted bool) {
+	if cfg.MaxErrors < 0 {
+		panic("`cfg.MaxErrors` must not be negative")
+	}
+
+	if cfg.MaxErrorRate < 0 || cfg.MaxErrorRate >= 1 {
+		panic("`cfg.MaxErrorRate` must be within [0, 1)")
+	}
+
+	if cfg.MinCompleted < 0 {
+		panic("`cfg.MinCompleted` must not be negative")
+	}
+
+	if cfg.MaxErrorRate > 0 && cfg.MinCompleted == 0 {
+		panic("`cfg.MinCompleted` must be greater than zero, if `cfg.MaxErrorRate` is set")
+	}
+
+	if cfg.MaxErrors == 0 && cfg.MaxErrorRate == 0 {
+		panic("at least one of `cfg.MaxErrors` and `cfg.MaxErrorRate` must be set")
+	}
+
+	gs := b.prepareGoroutines("ErrorBudget")
+	gs, fnRepanic := b.catchPanics(gs)
+
+	ctx, cancel := b.executionContext()
+	defer cancel()
+
+	mu := new(sync.Mutex)
+	completed, failures := 0, 0
+
+	errs = make([]error, len(gs))
+
+	b.execute(ctx, gs, func(i int, err error) {
+		errs[i] = err
+
+		if b.goroutineConfigs[i].optional {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// results of goroutines cancelled because of the exhausted budget must not affect anything.
+		if exhausted {
+			return
+		}
+
+		completed++
+		if err != nil {
+			failures++
+		}
+
+		exhaustedByCount := cfg.MaxErrors > 0 && failures > cfg.MaxErrors
+		exhaustedByRate := cfg.MaxErrorRate > 0 &&
+			completed >= cfg.MinCompleted &&
+			float64(failures)/float64(completed) > cfg.MaxErrorRate
+
+		if exhaustedByCount || exhaustedByRate {
+			exhausted = true
+			cancel()
+		}
+	})
+
+	fnRepanic()
+
+	return errs, exhausted
+}
+
+// Execution - Sequential
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Sequential executes goroutines one by one in the adding order on the calling goroutine
+// (e.g. for ordered side effects, or to debug the same Batch without concurrency).
+// Once the context is done, the rest goroutines are not started, and the context error is used as their results.
+// Batch.Limit makes no sense here, so it is ignored.
+//
+// Returns a slice of errors: index `i` matches `i`-th added goroutine.
+//
+// Panics if:
+//   - `mode` is unknown
+//   - no goroutines were added to the Batch
+//   - any goroutine panicked and Batch.PropagatePanics is enabled (with *GoroutinePanic)
+//     -- panicked goroutine is treated as failed, so the rest goroutines are executed according to the `mode`.
+//     Without Batch.PropagatePanics, panics are not recovered at all, as goroutines run on the calling goroutine.
+func (b *Batch) Sequential(mode SequentialMode) []error {
+	if mode != SequentialStopOnError && mode != SequentialRunAll {
+		panic("`mode` is unknown")
+	}
+
+	gs := b.prepareGoroutines("Sequential")
+	gs, fnRepanic := b.catchPanics(gs)
+
+	ctx, cancel := b.executionContext()
+	defer cancel()
+
+	errs := make([]error, len(gs))
+	failed := false
+
+	for i, g := range gs {
+		switch {
+		case failed && mode == SequentialStopOnError:
+			errs[i] = ErrSkipped
+		case ctx.Err() != nil:
+			errs[i] = ctx.Err()
+		default:
+			errs[i] = g(ctx)
+			failed = failed || errs[i] != nil
+		}
+	}
+
+	fnRepanic()
+
+	return errs
 }
 
 // Execution - Async
@@ -214,21 +814,16 @@ func (b *Batch) CancelOnError() error {
 func (b *Batch) async(gs []Goroutine, errChBufferSize uint) <-chan error {
 	errCh := make(chan error, errChBufferSize)
 
+	ctx, cancel := b.executionContext()
+
 	go func(errCh chan<- error, gs []Goroutine, ctx context.Context) {
 		defer close(errCh)
+		defer cancel()
 
-		wg := new(sync.WaitGroup)
-		wg.Add(len(gs))
-
-		for _, g := range gs {
-			go func(g Goroutine) {
-				defer wg.Done()
-				errCh <- g(ctx)
-			}(g)
-		}
-
-		wg.Wait()
-	}(errCh, gs, b.ctx)
+		b.execute(ctx, gs, func(i int, err error) {
+			errCh <- err
+		})
+	}(errCh, gs, ctx)
 
 	return errCh
 }
@@ -239,18 +834,139 @@ func (b *Batch) async(gs []Goroutine, errChBufferSize uint) <-chan error {
 //
 // Panics if no goroutines were added to the Batch.
 func (b *Batch) Async() <-chan error {
-	gs := b.prepareGoroutines()
+	gs := b.prepareGoroutines("Async")
 
 	return b.async(gs, uint(len(gs)))
 }
 
 // AsyncBs is the same as Async, but with custom buffer size of the result channel.
+// Note: if the channel is not read, goroutines are blocked forever on sending results into the full buffer --
+// use Batch.Start, if results may be abandoned.
 //
 // Panics if no goroutines were added to the Batch.
 func (b *Batch) AsyncBs(errChBufferSize uint) <-chan error {
-	gs := b.prepareGoroutines()
+	gs := b.prepareGoroutines("AsyncBs")
 
 	return b.async(gs, errChBufferSize)
 }
 
+// Execution - Start
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Start executes all goroutines asynchronously and returns a handle to control the execution.
+//
+// Panics if no goroutines were added to the Batch.
+func (b *Batch) Start() *AsyncHandle {
+	gs := b.prepareGoroutines("Start")
+
+	ctx, cancel := b.executionContext()
+
+	h := &AsyncHandle{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		errs:    make([]error, len(gs)),
+		results: make(chan IndexedError, len(gs)),
+	}
+
+	go func(h *AsyncHandle, gs []Goroutine, ctx context.Context) {
+		// results channel must be closed before `done`, so it's closed for sure once `done` is closed.
+		defer close(h.done)
+		defer close(h.results)
+		defer h.cancel()
+
+		b.execute(ctx, gs, func(i int, err error) {
+			h.errs[i] = err
+			h.results <- IndexedError{Index: i, Err: err}
+		})
+	}(h, gs, ctx)
+
+	return h
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Async handle
+// ---------------------------------------------------------------------------------------------------------------------
+
+// IndexedError is a result of the `Index`-th goroutine.
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
+// AsyncHandle controls the asynchronous execution started via Batch.Start.
+//
+// Results are buffered for all goroutines, so the handle can be abandoned at any moment
+// without leaking goroutines -- see AsyncHandle.Discard.
+//
+// Thread-safe.
+type AsyncHandle struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	errs    []error
+	results chan IndexedError
+}
+
+// Cancel cancels the context passed to goroutines. Does not wait for goroutines completion.
+func (h *AsyncHandle) Cancel() {
+	h.cancel()
+}
+
+// Done returns a channel, which is closed once all goroutines are completed.
+func (h *AsyncHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait waits for all goroutines completion.
+// Returns a slice of errors: index `i` matches `i`-th added goroutine.
+func (h *AsyncHandle) Wait() []error {
+	<-h.done
+
+	errs := make([]error, len(h.errs))
+	copy(errs, h.errs)
+
+	return errs
+}
+
+// Results returns a channel streaming index-tagged results in the completion order.
+// The channel is closed once all goroutines are completed.
+//
+// Note: the channel is the same for all calls, so each result is received only once.
+func (h *AsyncHandle) Results() <-chan IndexedError {
+	return h.results
+}
+
+// Discard cancels the execution and abandons the results:
+// goroutines complete in background, and no reading of results or waiting is required.
+func (h *AsyncHandle) Discard() {
+	h.cancel()
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Panic
+// ---------------------------------------------------------------------------------------------------------------------
+
+// GoroutinePanic is a panic value used to propagate a goroutine panic to the calling goroutine
+// (see Batch.PropagatePanics).
+type GoroutinePanic struct {
+	// Index of the panicked goroutine in the adding order.
+	Index int
+	// Value is the original panic value.
+	Value any
+	// Stack of the panicked goroutine.
+	Stack []byte
+}
+
+func (p *GoroutinePanic) Error() string {
+	return fmt.Sprintf("goroutine #%d panicked: %v\n\n%s", p.Index, p.Value, p.Stack)
+}
+
+// Unwrap returns the original panic value, if it is an error.
+func (p *GoroutinePanic) Unwrap() error {
+	if err, ok := p.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
 // ---------------------------------------------------------------------------------------------------------------------
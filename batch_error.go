@@ -0,0 +1,109 @@
+package goroutiner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// BatchError aggregates errors of the Batch goroutines (see Batch.WaitErr).
+type BatchError struct {
+	// Errors -- index `i` matches `i`-th added goroutine, nil for succeeded ones.
+	Errors []error
+	// Names -- index `i` matches `i`-th added goroutine, empty for unnamed ones (see Batch.AddNamed).
+	// Can be nil, if names are unknown (e.g. for NewBatchError).
+	Names []string
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// NewBatchError returns *BatchError for index-aligned errors `errs` (e.g. returned by Batch.Wait),
+// or nil, if all of them are nil.
+func NewBatchError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return &BatchError{Errors: append([]error(nil), errs...)}
+		}
+	}
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Failed returns indexes of failed goroutines in ascending order.
+func (e *BatchError) Failed() []int {
+	failed := make([]int, 0)
+
+	for i, err := range e.Errors {
+		if err != nil {
+			failed = append(failed, i)
+		}
+	}
+
+	return failed
+}
+
+// FailedCount returns the number of failed goroutines.
+func (e *BatchError) FailedCount() int {
+	return len(e.Failed())
+}
+
+func (e *BatchError) Error() string {
+	sb := new(strings.Builder)
+
+	_, _ = fmt.Fprintf(sb, "%d of %d goroutine(s) failed", e.FailedCount(), len(e.Errors))
+
+	for _, i := range e.Failed() {
+		if i < len(e.Names) && e.Names[i] != "" {
+			_, _ = fmt.Fprintf(sb, "\n  goroutine #%d %q: %v", i, e.Names[i], e.Errors[i])
+		} else {
+			_, _ = fmt.Fprintf(sb, "\n  goroutine #%d: %v", i, e.Errors[i])
+		}
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the error of the first failed goroutine.
+func (e *BatchError) Unwrap() error {
+	for _, err := range e.Errors {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Is reports whether any goroutine error matches the `target`.
+func (e *BatchError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if err != nil && errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first goroutine error matching the `target`.
+func (e *BatchError) As(target any) bool {
+	for _, err := range e.Errors {
+		if err != nil && errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
@@ -0,0 +1,268 @@
+package goroutiner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// ErrSkipped is matched (via errors.Is) by errors of goroutines, which were skipped by the execution strategy.
+var ErrSkipped = errors.New("goroutine skipped")
+
+// SkippedError is a result of the graph node, which was not executed because its dependency failed.
+type SkippedError struct {
+	Node       string
+	Dependency string
+	// Err is the error of the failed dependency.
+	Err error
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("%q skipped: dependency %q failed: %v", e.Node, e.Dependency, e.Err)
+}
+
+func (e *SkippedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SkippedError) Is(target error) bool {
+	return target == ErrSkipped
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// AddNode adds a new named goroutine (graph node), which depends on other named goroutines
+// -- see Batch.Add and Batch.Graph.
+// Dependencies can be added later, and are validated on execution only.
+//
+// Panics if:
+//   - `name` is empty
+//   - `name` is already used in the Batch
+//   - `deps` contains an empty name
+//   - `fn` is nil
+//   - `mws` contains nil
+func (b *Batch) AddNode(name string, deps []string, fn Goroutine, mws ...Middleware) *Batch {
+	if name == "" {
+		panic("`name` must not be empty")
+	}
+
+	b.checkNameIsUnused(name)
+
+	for _, dep := range deps {
+		if dep == "" {
+			panic("`deps` must contain no empty names")
+		}
+	}
+
+	b.Add(fn, mws...)
+
+	cfg := b.goroutineConfigs[len(b.goroutineConfigs)-1]
+	cfg.name = name
+	cfg.deps = append([]string(nil), deps...)
+
+	return b
+}
+
+// ValidateGraph checks, that all dependencies of the goroutines are known, and there are no cycles.
+func (b *Batch) ValidateGraph() error {
+	_, err := b.graph()
+
+	return err
+}
+
+// graph returns indexes of dependents of each goroutine.
+func (b *Batch) graph() ([][]int, error) {
+	indexes := make(map[string]int)
+	for i, cfg := range b.goroutineConfigs {
+		if cfg.name != "" {
+			indexes[cfg.name] = i
+		}
+	}
+
+	dependents := make([][]int, len(b.goroutineConfigs))
+
+	for i, cfg := range b.goroutineConfigs {
+		for _, dep := range cfg.deps {
+			j, ok := indexes[dep]
+			if !ok {
+				return nil, fmt.Errorf("%q depends on unknown %q", cfg.name, dep)
+			}
+
+			if j == i {
+				return nil, fmt.Errorf("%q depends on itself", cfg.name)
+			}
+
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// Kahn's algorithm: nodes, which are never resolved, are in (or depend on) a cycle.
+	inDegree := make([]int, len(dependents))
+	queue := make([]int, 0, len(dependents))
+
+	for i, cfg := range b.goroutineConfigs {
+		inDegree[i] = len(cfg.deps)
+		if inDegree[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+
+	for resolved := 0; resolved < len(queue); resolved++ {
+		for _, j := range dependents[queue[resolved]] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	if len(queue) < len(dependents) {
+		unresolved := make([]string, 0)
+		for i, d := range inDegree {
+			if d > 0 {
+				unresolved = append(unresolved, fmt.Sprintf("%q", b.goroutineConfigs[i].name))
+			}
+		}
+
+		return nil, fmt.Errorf("dependency cycle among %s", strings.Join(unresolved, ", "))
+	}
+
+	return dependents, nil
+}
+
+// Execution - Graph
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Graph executes all goroutines respecting their dependencies (see Batch.AddNode):
+// each goroutine is started as soon as all its dependencies succeed.
+// If any dependency fails, the goroutine is skipped with *SkippedError (matching ErrSkipped).
+// Once the context is done, goroutines are neither started nor skipped anymore,
+// and the context error is used as the result of all of them, which have not been started or skipped yet.
+//
+// With Batch.Limit, goroutines with succeeded dependencies are queued and started in the adding order.
+//
+// Returns a slice of errors: index `i` matches `i`-th added goroutine.
+//
+// Panics if:
+//   - no goroutines were added to the Batch
+//   - dependencies are invalid (see Batch.ValidateGraph)
+//   - any goroutine panicked and Batch.PropagatePanics is enabled (with *GoroutinePanic)
+func (b *Batch) Graph() []error {
+	gs := b.prepareGoroutines("Graph")
+
+	dependents, err := b.graph()
+	if err != nil {
+		panic(fmt.Sprintf("invalid graph: %v", err))
+	}
+
+	gs, fnRepanic := b.catchPanics(gs)
+
+	ctx, cancel := b.executionContext()
+	defer cancel()
+
+	type Result = struct {
+		i   int
+		err error
+	}
+
+	errs := make([]error, len(gs))
+	resolved := make([]bool, len(gs))
+	pending := make([]int, len(gs))
+	// indexes of goroutines with succeeded dependencies in ascending order.
+	ready := make([]int, 0)
+
+	for i, cfg := range b.goroutineConfigs {
+		pending[i] = len(cfg.deps)
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	// resolve sets the result of the `i`-th goroutine, and skips or readies its dependents.
+	var resolve func(i int, err error)
+	resolve = func(i int, err error) {
+		errs[i] = err
+		resolved[i] = true
+
+		for _, j := range dependents[i] {
+			// already skipped because of another failed dependency
+			if resolved[j] {
+				continue
+			}
+
+			if err != nil {
+				resolve(j, &SkippedError{
+					Node:       b.goroutineConfigs[j].name,
+					Dependency: b.goroutineConfigs[i].name,
+					Err:        err,
+				})
+				continue
+			}
+
+			pending[j]--
+			if pending[j] == 0 {
+				k := sort.SearchInts(ready, j)
+				ready = append(ready, 0)
+				copy(ready[k+1:], ready[k:])
+				ready[k] = j
+			}
+		}
+	}
+
+	// buffered for all goroutines, so they never block.
+	resCh := make(chan Result, len(gs))
+	running := 0
+
+	// goroutines are started by this loop only, so the concurrency limit bounds the number of goroutines too.
+	for {
+		for len(ready) > 0 && (b.limit == 0 || running < b.limit) && ctx.Err() == nil {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+
+			go func(i int, g Goroutine) {
+				resCh <- Result{i: i, err: g(ctx)}
+			}(i, gs[i])
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-resCh
+		running--
+
+		if ctx.Err() != nil {
+			errs[res.i] = res.err
+			resolved[res.i] = true
+			continue
+		}
+
+		resolve(res.i, res.err)
+	}
+
+	for i := range errs {
+		if !resolved[i] {
+			errs[i] = ctx.Err()
+		}
+	}
+
+	fnRepanic()
+
+	return errs
+}
+
+// GraphErr is the same as Batch.Graph, but returns a single *BatchError aggregating all errors
+// (nil, if all goroutines succeeded) with names of goroutines -- see Batch.WaitErr.
+func (b *Batch) GraphErr() error {
+	return b.namedBatchError(b.Graph())
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
@@ -0,0 +1,51 @@
+package goroutiner
+
+import (
+	"context"
+	"time"
+)
+
+// GoroutineInfo describes the running goroutine -- see InfoFromContext.
+type GoroutineInfo struct {
+	// Index of the goroutine in the adding order.
+	Index int
+	// Name of the goroutine (see Batch.AddNamed and WithName), empty for unnamed goroutines.
+	Name string
+	// BatchID is unique for each Batch (and for each TypedBatch, KeyedBatch, Saga)
+	// within the process, but is the same for all executions of the same Batch.
+	BatchID uint64
+	// Strategy is the name of the execution strategy method (e.g. "Wait"),
+	// or "SagaCompensation" for compensations of the Saga steps.
+	Strategy string
+	// Labels of the goroutine (see WithLabels). Must not be modified.
+	Labels map[string]string
+	// Timeout of the goroutine (see WithTimeout), zero if not set.
+	Timeout time.Duration
+	// Optional is true for optional goroutines (see WithOptional).
+	Optional bool
+	// StartDelay of the goroutine (see WithStartDelay), zero if not set.
+	StartDelay time.Duration
+	// StartedAt is the time, when the goroutine (including all its middleware) was started.
+	StartedAt time.Time
+}
+
+type goroutineInfoKey struct{}
+
+// InfoFromContext returns the info about the goroutine, which received the `ctx`
+// (the info is available for all middleware too).
+// Returns false, if the `ctx` was not passed by the Batch (or its counterparts).
+func InfoFromContext(ctx context.Context) (GoroutineInfo, bool) {
+	info, ok := ctx.Value(goroutineInfoKey{}).(GoroutineInfo)
+
+	return info, ok
+}
+
+// withInfo wraps the goroutine `g` to pass the `info` via the context.
+func withInfo(g Goroutine, info GoroutineInfo) Goroutine {
+	return func(ctx context.Context) error {
+		info := info
+		info.StartedAt = time.Now()
+
+		return g(context.WithValue(ctx, goroutineInfoKey{}, info))
+	}
+}
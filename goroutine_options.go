@@ -0,0 +1,150 @@
+package goroutiner
+
+import (
+	"context"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// GoroutineOption configures the goroutine added via Batch.AddWith.
+type GoroutineOption func(cfg *goroutineConfig)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Options
+// ---------------------------------------------------------------------------------------------------------------------
+
+// WithName gives the `name` to the goroutine -- see Batch.AddNamed.
+//
+// Panics if `name` is empty.
+func WithName(name string) GoroutineOption {
+	if name == "" {
+		panic("`name` must not be empty")
+	}
+
+	return func(cfg *goroutineConfig) {
+		cfg.name = name
+	}
+}
+
+// WithLabels attaches `labels` to the goroutine (available via GoroutineInfo, e.g. for logging or metrics).
+// Labels of several options are merged, the last value of the same key wins.
+func WithLabels(labels map[string]string) GoroutineOption {
+	return func(cfg *goroutineConfig) {
+		if cfg.labels == nil {
+			cfg.labels = make(map[string]string, len(labels))
+		}
+
+		for k, v := range labels {
+			cfg.labels[k] = v
+		}
+	}
+}
+
+// WithMiddleware adds individual middleware -- see Batch.Add.
+//
+// Panics if `mws` contains nil.
+func WithMiddleware(mws ...Middleware) GoroutineOption {
+	for _, mw := range mws {
+		if mw == nil {
+			panic("`mws` must contain no `nil` elements")
+		}
+	}
+
+	return func(cfg *goroutineConfig) {
+		cfg.mws = append(cfg.mws, mws...)
+	}
+}
+
+// WithTimeout limits the goroutine execution time via MwTimeout with TimeoutWait policy
+// (each attempt separately, if WithRetry is used too).
+// Applied after all individual middleware.
+//
+// Panics if `d` <= 0.
+func WithTimeout(d time.Duration) GoroutineOption {
+	if d <= 0 {
+		panic("`d` must be greater than zero")
+	}
+
+	return func(cfg *goroutineConfig) {
+		cfg.timeout = d
+	}
+}
+
+// WithRetry re-runs the failed goroutine via MwRetry.
+// Applied after all individual middleware.
+//
+// Panics if `retryCfg` is invalid -- see MwRetry.
+func WithRetry(retryCfg RetryConfig) GoroutineOption {
+	mw := MwRetry(retryCfg)
+
+	return func(cfg *goroutineConfig) {
+		cfg.retry = mw
+	}
+}
+
+// WithOptional marks the goroutine as optional: its failure does not cancel other goroutines
+// in Batch.CancelOnError (and is not returned by it).
+func WithOptional() GoroutineOption {
+	return func(cfg *goroutineConfig) {
+		cfg.optional = true
+	}
+}
+
+// WithStartDelay delays the goroutine start (with all its middleware).
+// If the context is done during the delay, the goroutine is not started, and the context error is used as its result.
+// The delay counts towards Batch.Limit, as the goroutine is considered running.
+//
+// Panics if `d` < 0.
+func WithStartDelay(d time.Duration) GoroutineOption {
+	if d < 0 {
+		panic("`d` must not be negative")
+	}
+
+	return func(cfg *goroutineConfig) {
+		cfg.startDelay = d
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Applying
+// ---------------------------------------------------------------------------------------------------------------------
+
+// fnWithOptions returns the goroutine function wrapped with timeout and retry options
+// (i.e. they are applied after all individual middleware).
+func (cfg *goroutineConfig) fnWithOptions() Goroutine {
+	g := cfg.fn
+
+	if cfg.timeout > 0 {
+		g = MwTimeout(cfg.timeout, TimeoutWait)(g)
+	}
+
+	if cfg.retry != nil {
+		g = cfg.retry(g)
+	}
+
+	return g
+}
+
+// delayStart wraps the prepared goroutine `g` to wait for the start delay of the `cfg`.
+func (cfg *goroutineConfig) delayStart(g Goroutine) Goroutine {
+	if cfg.startDelay == 0 {
+		return g
+	}
+
+	return func(ctx context.Context) error {
+		timer := time.NewTimer(cfg.startDelay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return g(ctx)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
@@ -0,0 +1,187 @@
+package goroutiner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// KeyedBatch is a counterpart of the Batch, where each goroutine has a key:
+// goroutines with equal keys are executed strictly one by one in the adding order,
+// while goroutines with different keys are executed concurrently.
+//
+// Example use case: process events of each customer in order, but different customers in parallel.
+//
+// Not thread-safe, as there is no practical need to make it thread‑safe.
+type KeyedBatch[K comparable] struct {
+	// batch keeps the context, middleware, settings and goroutine configs.
+	batch *Batch
+	keys  []K
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// NewKeyedBatch creates a new keyed batch with the given context and optional batch middleware.
+// It is the same as Goroutiner.Batch, but for goroutines with keys.
+//
+// Panics if:
+//   - `g` is nil
+//   - `ctx` is nil
+//   - `mws` contains nil
+func NewKeyedBatch[K comparable](g *Goroutiner, ctx context.Context, mws ...Middleware) *KeyedBatch[K] {
+	if g == nil {
+		panic("`g` must not be `nil`")
+	}
+
+	return &KeyedBatch[K]{
+		batch: g.Batch(ctx, mws...),
+		keys:  make([]K, 0),
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Add adds a new goroutine with the `key` -- see Batch.Add.
+func (kb *KeyedBatch[K]) Add(key K, fn Goroutine, mws ...Middleware) *KeyedBatch[K] {
+	kb.batch.Add(fn, mws...)
+	kb.keys = append(kb.keys, key)
+
+	return kb
+}
+
+// Limit -- see Batch.Limit.
+// Since goroutines with equal keys are never executed at the same time, it also limits the number of keys in progress.
+func (kb *KeyedBatch[K]) Limit(n int) *KeyedBatch[K] {
+	kb.batch.Limit(n)
+
+	return kb
+}
+
+// Timeout -- see Batch.Timeout.
+func (kb *KeyedBatch[K]) Timeout(d time.Duration) *KeyedBatch[K] {
+	kb.batch.Timeout(d)
+
+	return kb
+}
+
+// Deadline -- see Batch.Deadline.
+func (kb *KeyedBatch[K]) Deadline(t time.Time) *KeyedBatch[K] {
+	kb.batch.Deadline(t)
+
+	return kb
+}
+
+// PropagatePanics -- see Batch.PropagatePanics.
+func (kb *KeyedBatch[K]) PropagatePanics() *KeyedBatch[K] {
+	kb.batch.PropagatePanics()
+
+	return kb
+}
+
+// Executing
+// ---------------------------------------------------------------------------------------------------------------------
+
+// execute runs a sequence of goroutines for each key concurrently and passes the result of the `i`-th goroutine
+// to `fnDone` (can be called concurrently).
+// Once the context is done, the rest goroutines of each sequence are not started,
+// and the context error is used as their result.
+// Blocks until all started goroutines are completed.
+func (kb *KeyedBatch[K]) execute(ctx context.Context, gs []Goroutine, fnDone func(i int, err error)) {
+	order := make([]K, 0)
+	sequences := make(map[K][]int)
+
+	for i, key := range kb.keys {
+		if _, ok := sequences[key]; !ok {
+			order = append(order, key)
+		}
+		sequences[key] = append(sequences[key], i)
+	}
+
+	seqGs := make([]Goroutine, len(order))
+
+	for s, key := range order {
+		func(indexes []int) {
+			seqGs[s] = func(ctx context.Context) error {
+				for _, i := range indexes {
+					if err := ctx.Err(); err != nil {
+						fnDone(i, err)
+						continue
+					}
+
+					fnDone(i, gs[i](ctx))
+				}
+
+				return nil
+			}
+		}(sequences[key])
+	}
+
+	kb.batch.execute(ctx, seqGs, func(s int, err error) {
+		// sequences always return nil, so an error means, that the sequence has not been started.
+		if err != nil {
+			for _, i := range sequences[order[s]] {
+				fnDone(i, err)
+			}
+		}
+	})
+}
+
+// Execution - Wait
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Wait -- see Batch.Wait.
+func (kb *KeyedBatch[K]) Wait() []error {
+	gs := kb.batch.prepareGoroutines("Wait")
+	gs, fnRepanic := kb.batch.catchPanics(gs)
+
+	ctx, cancel := kb.batch.executionContext()
+	defer cancel()
+
+	errs := make([]error, len(gs))
+	kb.execute(ctx, gs, func(i int, err error) {
+		errs[i] = err
+	})
+
+	fnRepanic()
+
+	return errs
+}
+
+// Execution - CancelOnError
+// ---------------------------------------------------------------------------------------------------------------------
+
+// CancelOnError -- see Batch.CancelOnError.
+// Goroutines not started yet because of the cancellation are not started at all.
+func (kb *KeyedBatch[K]) CancelOnError() error {
+	gs := kb.batch.prepareGoroutines("CancelOnError")
+	gs, fnRepanic := kb.batch.catchPanics(gs)
+
+	ctx, cancel := kb.batch.executionContext()
+	defer cancel()
+
+	var firstErr error
+	errOnce := new(sync.Once)
+
+	kb.execute(ctx, gs, func(i int, err error) {
+		if err != nil {
+			errOnce.Do(func() {
+				firstErr = err
+				cancel()
+			})
+		}
+	})
+
+	fnRepanic()
+
+	return firstErr
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
@@ -0,0 +1,199 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/sync/semaphore"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// ErrBulkheadFull is returned by MwBulkhead instead of executing the goroutine,
+// when the waiting queue of the Bulkhead is full.
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// ErrBulkheadTimeout is returned by MwBulkhead instead of executing the goroutine,
+// when the goroutine has been waiting for the Bulkhead longer than the acquire timeout.
+var ErrBulkheadTimeout = errors.New("bulkhead acquire timeout")
+
+// BulkheadConfig configures the Bulkhead.
+type BulkheadConfig struct {
+	// Capacity is the total weight of goroutines running at the same time.
+	Capacity int64
+	// MaxQueue is the maximum number of goroutines waiting for the capacity.
+	// Others are rejected with ErrBulkheadFull. Zero means no limit.
+	MaxQueue int
+	// AcquireTimeout limits the waiting time, after which ErrBulkheadTimeout is returned. Zero means no limit.
+	AcquireTimeout time.Duration
+}
+
+// BulkheadStats is a snapshot of the Bulkhead state and counters.
+type BulkheadStats struct {
+	// InUse is the total weight of running goroutines.
+	InUse int64
+	// Waiting is the number of goroutines waiting for the capacity.
+	Waiting int
+
+	// Rejected is the number of goroutines rejected with ErrBulkheadFull.
+	Rejected uint64
+	// TimedOut is the number of goroutines rejected with ErrBulkheadTimeout.
+	TimedOut uint64
+}
+
+// Bulkhead is a weighted semaphore shared by all goroutines wrapped with the same instance
+// via MwBulkhead (even from different batches and Goroutiner instances).
+// Unlike Batch.Limit, it limits access to a resource regardless of the batch.
+//
+// Waiting goroutines acquire the capacity in FIFO order.
+//
+// Thread-safe.
+type Bulkhead struct {
+	cfg BulkheadConfig
+	sem *semaphore.Weighted
+
+	mu    sync.Mutex
+	stats BulkheadStats
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// NewBulkhead
+//
+// Panics if:
+//   - `cfg.Capacity` <= 0
+//   - `cfg.MaxQueue` < 0
+//   - `cfg.AcquireTimeout` < 0
+func NewBulkhead(cfg BulkheadConfig) *Bulkhead {
+	if cfg.Capacity <= 0 {
+		panic("`cfg.Capacity` must be greater than zero")
+	}
+
+	if cfg.MaxQueue < 0 {
+		panic("`cfg.MaxQueue` must not be negative")
+	}
+
+	if cfg.AcquireTimeout < 0 {
+		panic("`cfg.AcquireTimeout` must not be negative")
+	}
+
+	return &Bulkhead{
+		cfg: cfg,
+		sem: semaphore.NewWeighted(cfg.Capacity),
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Stats returns the current state and counters of the bulkhead.
+func (bh *Bulkhead) Stats() BulkheadStats {
+	bh.mu.Lock()
+	defer bh.mu.Unlock()
+
+	return bh.stats
+}
+
+// acquire takes the `weight` of the capacity, waiting for it, if the queue is not full.
+func (bh *Bulkhead) acquire(ctx context.Context, weight int64) error {
+	bh.mu.Lock()
+
+	if bh.sem.TryAcquire(weight) {
+		bh.stats.InUse += weight
+		bh.mu.Unlock()
+		return nil
+	}
+
+	if bh.cfg.MaxQueue > 0 && bh.stats.Waiting >= bh.cfg.MaxQueue {
+		bh.stats.Rejected++
+		bh.mu.Unlock()
+		return ErrBulkheadFull
+	}
+
+	bh.stats.Waiting++
+	bh.mu.Unlock()
+
+	acquireCtx := ctx
+	if bh.cfg.AcquireTimeout > 0 {
+		var cancel context.CancelFunc
+		acquireCtx, cancel = context.WithTimeout(ctx, bh.cfg.AcquireTimeout)
+		defer cancel()
+	}
+
+	err := bh.sem.Acquire(acquireCtx, weight)
+
+	// the capacity can be acquired right after the context is done -- the goroutine must not be started anyway.
+	if err == nil && ctx.Err() != nil {
+		bh.sem.Release(weight)
+		err = ctx.Err()
+	}
+
+	bh.mu.Lock()
+	defer bh.mu.Unlock()
+
+	bh.stats.Waiting--
+
+	if err != nil {
+		// the goroutine context is done -- its error is more relevant.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		bh.stats.TimedOut++
+		return ErrBulkheadTimeout
+	}
+
+	bh.stats.InUse += weight
+
+	return nil
+}
+
+// release returns the `weight` of the capacity.
+func (bh *Bulkhead) release(weight int64) {
+	bh.mu.Lock()
+	bh.stats.InUse -= weight
+	bh.mu.Unlock()
+
+	bh.sem.Release(weight)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Middleware
+// ---------------------------------------------------------------------------------------------------------------------
+
+// MwBulkhead creates a middleware that executes goroutines within the `bh` capacity,
+// each goroutine takes the `weight` of the capacity (e.g. heavy goroutines can take more).
+// If the capacity is not available, the goroutine waits for it, unless the queue is full (ErrBulkheadFull)
+// or the acquire timeout is reached (ErrBulkheadTimeout). If the context is done first, its error is returned.
+//
+// Panics if:
+//   - `bh` is nil
+//   - `weight` is not within [1, capacity of the `bh`]
+func MwBulkhead(bh *Bulkhead, weight int64) Middleware {
+	if bh == nil {
+		panic("`bh` must not be `nil`")
+	}
+
+	if weight <= 0 || weight > bh.cfg.Capacity {
+		panic("`weight` must be within [1, capacity]")
+	}
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) error {
+			if err := bh.acquire(ctx, weight); err != nil {
+				return err
+			}
+			defer bh.release(weight)
+
+			return g(ctx)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
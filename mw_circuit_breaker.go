@@ -0,0 +1,406 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// CircuitState is a state of the CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed -- goroutines are executed, failures are counted.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen -- goroutines are not executed, ErrCircuitOpen is returned instead.
+	CircuitOpen
+	// CircuitHalfOpen -- a limited number of probe goroutines are executed to decide,
+	// whether the circuit must be closed or opened again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrCircuitOpen is returned by MwCircuitBreaker instead of executing the goroutine, while the circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerConfig configures the CircuitBreaker.
+// At least one of the opening conditions (ConsecutiveFailures or FailureRate) must be set.
+type CircuitBreakerConfig struct {
+	// ConsecutiveFailures opens the circuit after this number of failures in a row.
+	// Zero disables the condition.
+	ConsecutiveFailures int
+
+	// FailureRate opens the circuit, once the rate of failures within the rolling Window reaches this value.
+	// Must be within (0, 1]. Zero disables the condition.
+	FailureRate float64
+	// Window is the duration of the rolling window for FailureRate. Required, if FailureRate is set.
+	Window time.Duration
+	// MinRequests is the minimum number of completed goroutines within the Window to apply FailureRate.
+	MinRequests int
+
+	// OpenDuration is the time the circuit stays open before switching to half-open.
+	OpenDuration time.Duration
+	// HalfOpenProbes is the number of probe goroutines in half-open state:
+	// the circuit is closed, once all of them succeed, and opened again on any failure.
+	// Zero means 1.
+	HalfOpenProbes int
+
+	// FnIsFailure decides, whether the goroutine error is a failure. It receives non-nil errors only.
+	// Errors, which are not failures, are ignored -- they are counted neither as failures, nor as successes.
+	// Nil means any error, except context.Canceled (e.g. caused by CancelOnError of another goroutine).
+	FnIsFailure func(err error) bool
+	// FnOnStateChange (optional) is called after each state change.
+	FnOnStateChange func(from CircuitState, to CircuitState)
+}
+
+// CircuitBreaker keeps the circuit state shared by all goroutines wrapped with the same instance
+// via MwCircuitBreaker (even from different batches and Goroutiner instances).
+//
+// Thread-safe.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu                  sync.Mutex
+	state               CircuitState
+	openedAt            time.Time
+	consecutiveFailures int
+	window              *rollingWindow
+	probesRunning       int
+	probesSucceeded     int
+	// generation is incremented on each state change, so results of goroutines started before are ignored.
+	generation uint64
+}
+
+// circuitOutcome is a result of the executed goroutine from the CircuitBreaker point of view.
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	// circuitIgnored -- the goroutine error is not a failure (see CircuitBreakerConfig.FnIsFailure).
+	circuitIgnored
+)
+
+// rollingWindow counts results within the window using a ring of buckets.
+type rollingWindow struct {
+	bucketDuration time.Duration
+	buckets        []windowBucket
+}
+
+type windowBucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+const rollingWindowBuckets = 10
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// NewCircuitBreaker
+//
+// Panics if:
+//   - both `cfg.ConsecutiveFailures` and `cfg.FailureRate` are zero
+//   - `cfg.ConsecutiveFailures` or `cfg.MinRequests` or `cfg.HalfOpenProbes` < 0
+//   - `cfg.FailureRate` is not within [0, 1]
+//   - `cfg.Window` <= 0, while `cfg.FailureRate` is set
+//   - `cfg.OpenDuration` <= 0
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.ConsecutiveFailures == 0 && cfg.FailureRate == 0 {
+		panic("either `cfg.ConsecutiveFailures` or `cfg.FailureRate` must be set")
+	}
+
+	if cfg.ConsecutiveFailures < 0 || cfg.MinRequests < 0 || cfg.HalfOpenProbes < 0 {
+		panic("`cfg.ConsecutiveFailures`, `cfg.MinRequests` and `cfg.HalfOpenProbes` must not be negative")
+	}
+
+	if cfg.FailureRate < 0 || cfg.FailureRate > 1 {
+		panic("`cfg.FailureRate` must be within [0, 1]")
+	}
+
+	if cfg.FailureRate > 0 && cfg.Window <= 0 {
+		panic("`cfg.Window` must be greater than zero, if `cfg.FailureRate` is set")
+	}
+
+	if cfg.OpenDuration <= 0 {
+		panic("`cfg.OpenDuration` must be greater than zero")
+	}
+
+	if cfg.HalfOpenProbes == 0 {
+		cfg.HalfOpenProbes = 1
+	}
+
+	if cfg.FnIsFailure == nil {
+		cfg.FnIsFailure = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+
+	cb := &CircuitBreaker{
+		cfg:   cfg,
+		state: CircuitClosed,
+	}
+
+	if cfg.FailureRate > 0 {
+		cb.window = &rollingWindow{
+			bucketDuration: cfg.Window / rollingWindowBuckets,
+			buckets:        make([]windowBucket, rollingWindowBuckets),
+		}
+		if cb.window.bucketDuration <= 0 {
+			cb.window.bucketDuration = 1
+		}
+	}
+
+	return cb
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	from, to := cb.refresh(time.Now())
+	state := cb.state
+	cb.mu.Unlock()
+
+	cb.notify(from, to)
+
+	return state
+}
+
+// allow reports, whether the goroutine can be executed, and whether it is a half-open probe.
+// The returned generation must be passed to done.
+func (cb *CircuitBreaker) allow() (isProbe bool, generation uint64, err error) {
+	cb.mu.Lock()
+	from, to := cb.refresh(time.Now())
+
+	switch cb.state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.probesRunning+cb.probesSucceeded >= cb.cfg.HalfOpenProbes {
+			err = ErrCircuitOpen
+		} else {
+			cb.probesRunning++
+			isProbe = true
+		}
+	}
+	generation = cb.generation
+	cb.mu.Unlock()
+
+	cb.notify(from, to)
+
+	return isProbe, generation, err
+}
+
+// done records the result of the executed goroutine.
+func (cb *CircuitBreaker) done(isProbe bool, generation uint64, outcome circuitOutcome) {
+	now := time.Now()
+
+	cb.mu.Lock()
+	from, to := cb.record(now, isProbe, generation, outcome)
+	cb.mu.Unlock()
+
+	cb.notify(from, to)
+}
+
+func (cb *CircuitBreaker) record(
+	now time.Time,
+	isProbe bool,
+	generation uint64,
+	outcome circuitOutcome,
+) (from CircuitState, to CircuitState) {
+	// results of goroutines (including probes) started before the state change are ignored.
+	if generation != cb.generation {
+		return cb.state, cb.state
+	}
+
+	isFailure := outcome == circuitFailure
+
+	if isProbe {
+		cb.probesRunning--
+
+		// the probe slot is released, but the probe neither succeeds, nor fails.
+		if outcome == circuitIgnored {
+			return cb.state, cb.state
+		}
+
+		if isFailure {
+			return cb.switchTo(now, CircuitOpen)
+		}
+
+		cb.probesSucceeded++
+		if cb.probesSucceeded >= cb.cfg.HalfOpenProbes {
+			return cb.switchTo(now, CircuitClosed)
+		}
+
+		return cb.state, cb.state
+	}
+
+	if outcome == circuitIgnored {
+		return cb.state, cb.state
+	}
+
+	if isFailure {
+		cb.consecutiveFailures++
+	} else {
+		cb.consecutiveFailures = 0
+	}
+
+	if cb.cfg.ConsecutiveFailures > 0 && cb.consecutiveFailures >= cb.cfg.ConsecutiveFailures {
+		return cb.switchTo(now, CircuitOpen)
+	}
+
+	if cb.window != nil {
+		cb.window.add(now, isFailure)
+
+		total, failures := cb.window.stats(now)
+		if total > 0 && total >= cb.cfg.MinRequests && float64(failures)/float64(total) >= cb.cfg.FailureRate {
+			return cb.switchTo(now, CircuitOpen)
+		}
+	}
+
+	return cb.state, cb.state
+}
+
+// refresh switches the open circuit to half-open, once the open duration is over.
+func (cb *CircuitBreaker) refresh(now time.Time) (from CircuitState, to CircuitState) {
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.cfg.OpenDuration {
+		return cb.switchTo(now, CircuitHalfOpen)
+	}
+
+	return cb.state, cb.state
+}
+
+func (cb *CircuitBreaker) switchTo(now time.Time, state CircuitState) (from CircuitState, to CircuitState) {
+	from = cb.state
+
+	cb.state = state
+	cb.generation++
+	cb.consecutiveFailures = 0
+	cb.probesRunning = 0
+	cb.probesSucceeded = 0
+
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+
+	if cb.window != nil {
+		cb.window.reset()
+	}
+
+	return from, state
+}
+
+// notify calls the state change callback (outside the lock, so the callback can use the CircuitBreaker).
+func (cb *CircuitBreaker) notify(from CircuitState, to CircuitState) {
+	if from != to && cb.cfg.FnOnStateChange != nil {
+		cb.cfg.FnOnStateChange(from, to)
+	}
+}
+
+// Rolling window
+// ---------------------------------------------------------------------------------------------------------------------
+
+func (w *rollingWindow) add(now time.Time, isFailure bool) {
+	start := now.Truncate(w.bucketDuration)
+	b := &w.buckets[int(start.UnixNano()/int64(w.bucketDuration))%len(w.buckets)]
+
+	if !b.start.Equal(start) {
+		*b = windowBucket{start: start}
+	}
+
+	b.total++
+	if isFailure {
+		b.failures++
+	}
+}
+
+func (w *rollingWindow) stats(now time.Time) (total int, failures int) {
+	since := now.Add(-w.bucketDuration * time.Duration(len(w.buckets)))
+
+	for _, b := range w.buckets {
+		if b.start.After(since) {
+			total += b.total
+			failures += b.failures
+		}
+	}
+
+	return total, failures
+}
+
+func (w *rollingWindow) reset() {
+	for i := range w.buckets {
+		w.buckets[i] = windowBucket{}
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Middleware
+// ---------------------------------------------------------------------------------------------------------------------
+
+// MwCircuitBreaker creates a middleware that executes goroutines through the `cb`.
+// While the circuit is open, goroutines are not executed, and ErrCircuitOpen is returned instead.
+//
+// Panics in goroutines are counted as failures (and propagated further).
+// Errors, which are not failures (see CircuitBreakerConfig.FnIsFailure), are ignored.
+//
+// Panics if `cb` is nil.
+func MwCircuitBreaker(cb *CircuitBreaker) Middleware {
+	if cb == nil {
+		panic("`cb` must not be `nil`")
+	}
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) (rErr error) {
+			isProbe, generation, err := cb.allow()
+			if err != nil {
+				return err
+			}
+
+			completed := false
+			defer func() {
+				outcome := circuitSuccess
+
+				switch {
+				case !completed: // i.e. panicked
+					outcome = circuitFailure
+				case rErr != nil && cb.cfg.FnIsFailure(rErr):
+					outcome = circuitFailure
+				case rErr != nil:
+					outcome = circuitIgnored
+				}
+
+				cb.done(isProbe, generation, outcome)
+			}()
+
+			rErr = g(ctx)
+			completed = true
+
+			return rErr
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
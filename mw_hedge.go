@@ -0,0 +1,261 @@
+package goroutiner
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HedgeConfig configures MwHedge.
+type HedgeConfig struct {
+	// Delay is the time to wait for the previous attempts, before starting the next one (hedge).
+	// If Percentile is set, Delay is used until enough latencies are observed.
+	Delay time.Duration
+	// MaxHedges is the maximum number of additional attempts. Must be > 0.
+	MaxHedges int
+
+	// Percentile (e.g. 0.95) makes the delay equal to the percentile of latencies of original (not hedged) attempts
+	// of successful executions, observed by the middleware. If a hedge wins, the time elapsed since the start
+	// of the original attempt is observed instead (the original latency is at least that).
+	// Latencies of winning hedges are not observed, as the fastest of several copies would make the delay
+	// shorter and shorter. Must be within [0, 1). Zero means the static Delay.
+	Percentile float64
+	// MinSamples is the number of observed latencies required to apply the Percentile. Zero means 1.
+	MinSamples int
+	// MaxSamples is the number of latest observed latencies used for the Percentile. Zero means 100.
+	MaxSamples int
+
+	// FnOnHedge (optional) is called before starting each hedge (`attempt` >= 1).
+	FnOnHedge func(attempt int, ctx context.Context)
+	// FnOnWin (optional) is called, once any attempt succeeds (`attempt` = 0 is the original one).
+	FnOnWin func(attempt int, latency time.Duration, ctx context.Context)
+}
+
+// hedgeLatencies keeps the latest observed latencies.
+type hedgeLatencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// MwHedge creates a middleware that starts additional copies (hedges) of the goroutine,
+// if previous attempts have not succeeded within the delay (see HedgeConfig),
+// or immediately, if all previous attempts have failed.
+// The first successful attempt wins: contexts of others are cancelled, and nil is returned without waiting for them.
+// Returns the error of the last failed attempt, if all attempts have failed.
+//
+// Observed latencies (see HedgeConfig.Percentile) are shared by all goroutines wrapped with the same middleware.
+//
+// Note: attempts are executed in separate goroutines, so the goroutine must be safe to execute concurrently.
+// Panics of attempts are propagated to the caller while it is waiting, and crash the process after that.
+//
+// Panics if:
+//   - `cfg.Delay` <= 0
+//   - `cfg.MaxHedges` <= 0
+//   - `cfg.Percentile` is not within [0, 1)
+//   - `cfg.MinSamples` or `cfg.MaxSamples` < 0
+//   - `cfg.MinSamples` > `cfg.MaxSamples` (if set)
+func MwHedge(cfg HedgeConfig) Middleware {
+	if cfg.Delay <= 0 {
+		panic("`cfg.Delay` must be greater than zero")
+	}
+
+	if cfg.MaxHedges <= 0 {
+		panic("`cfg.MaxHedges` must be greater than zero")
+	}
+
+	if cfg.Percentile < 0 || cfg.Percentile >= 1 {
+		panic("`cfg.Percentile` must be within [0, 1)")
+	}
+
+	if cfg.MinSamples < 0 || cfg.MaxSamples < 0 {
+		panic("`cfg.MinSamples` and `cfg.MaxSamples` must not be negative")
+	}
+
+	if cfg.MinSamples == 0 {
+		cfg.MinSamples = 1
+	}
+
+	if cfg.MaxSamples == 0 {
+		cfg.MaxSamples = 100
+	}
+
+	if cfg.MinSamples > cfg.MaxSamples {
+		panic("`cfg.MinSamples` must not be greater than `cfg.MaxSamples`")
+	}
+
+	latencies := &hedgeLatencies{samples: make([]time.Duration, 0, cfg.MaxSamples)}
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) error {
+			hCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			type Result = struct {
+				attempt    int
+				err        error
+				panicValue any
+				latency    time.Duration
+			}
+
+			// buffered for all attempts, so abandoned attempts never block.
+			resCh := make(chan Result, cfg.MaxHedges+1)
+			mu := new(sync.Mutex)
+			abandoned := false
+
+			started, running := 0, 0
+
+			startAttempt := func() {
+				attempt := started
+				started++
+				running++
+
+				if attempt > 0 && cfg.FnOnHedge != nil {
+					cfg.FnOnHedge(attempt, ctx)
+				}
+
+				go func() {
+					startedAt := time.Now()
+
+					defer func() {
+						if pv := recover(); pv != nil {
+							mu.Lock()
+							defer mu.Unlock()
+							if abandoned {
+								panic(pv)
+							}
+							resCh <- Result{attempt: attempt, panicValue: pv}
+						}
+					}()
+
+					err := g(hCtx)
+					resCh <- Result{attempt: attempt, err: err, latency: time.Since(startedAt)}
+				}()
+			}
+
+			// abandon stops waiting for attempts, but panics already received are still propagated.
+			abandon := func() {
+				mu.Lock()
+				defer mu.Unlock()
+
+				abandoned = true
+
+				for {
+					select {
+					case res := <-resCh:
+						if res.panicValue != nil {
+							panic(res.panicValue)
+						}
+					default:
+						return
+					}
+				}
+			}
+
+			delay := latencies.delay(cfg)
+
+			originalStartedAt := time.Now()
+			startAttempt()
+
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+
+			var lastErr error
+
+			for {
+				var timerCh <-chan time.Time
+				if started <= cfg.MaxHedges && ctx.Err() == nil {
+					timerCh = timer.C
+				}
+
+				select {
+				case <-timerCh:
+					startAttempt()
+					timer.Reset(delay)
+
+				case res := <-resCh:
+					running--
+
+					if res.panicValue != nil {
+						cancel()
+						abandon()
+						panic(res.panicValue)
+					}
+
+					if res.err == nil {
+						cancel()
+						abandon()
+
+						if res.attempt == 0 {
+							latencies.add(res.latency, cfg.MaxSamples)
+						} else {
+							latencies.add(time.Since(originalStartedAt), cfg.MaxSamples)
+						}
+						if cfg.FnOnWin != nil {
+							cfg.FnOnWin(res.attempt, res.latency, ctx)
+						}
+
+						return nil
+					}
+
+					lastErr = res.err
+
+					if running == 0 {
+						if started > cfg.MaxHedges || ctx.Err() != nil {
+							return lastErr
+						}
+
+						startAttempt()
+						resetTimer(timer, delay)
+					}
+				}
+			}
+		}
+	}
+}
+
+// delay returns the hedge delay according to the `cfg`.
+func (l *hedgeLatencies) delay(cfg HedgeConfig) time.Duration {
+	if cfg.Percentile == 0 {
+		return cfg.Delay
+	}
+
+	l.mu.Lock()
+	samples := make([]time.Duration, len(l.samples))
+	copy(samples, l.samples)
+	l.mu.Unlock()
+
+	if len(samples) < cfg.MinSamples {
+		return cfg.Delay
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	// nearest-rank method
+	rank := int(math.Ceil(cfg.Percentile*float64(len(samples)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	if samples[rank] <= 0 {
+		return 1
+	}
+
+	return samples[rank]
+}
+
+// add adds the `latency` replacing the oldest one, once `maxSamples` are collected.
+func (l *hedgeLatencies) add(latency time.Duration, maxSamples int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < maxSamples {
+		l.samples = append(l.samples, latency)
+		return
+	}
+
+	l.samples[l.next] = latency
+	l.next = (l.next + 1) % maxSamples
+}
@@ -0,0 +1,174 @@
+package goroutiner
+
+import (
+	"context"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// Logger is a minimal structured logger interface, which is satisfied by *slog.Logger (log/slog, Go 1.21+),
+// so the package does not depend on any particular logging library.
+// `args` are alternating keys and values, as in log/slog.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+// LogLevel defines the level of the MwLog record.
+type LogLevel int
+
+const (
+	// LogDefault -- the default level of the record (see LogConfig).
+	LogDefault LogLevel = iota
+	LogDebug
+	LogInfo
+	LogWarn
+	LogError
+	// LogOff -- the record is not logged.
+	LogOff
+)
+
+// LogConfig configures MwLog.
+type LogConfig struct {
+	Logger Logger
+
+	// StartLevel is the level of "goroutine started" record. Default is LogDebug.
+	StartLevel LogLevel
+	// SuccessLevel is the level of "goroutine succeeded" record. Default is LogDebug.
+	SuccessLevel LogLevel
+	// ErrorLevel is the level of "goroutine failed" record. Default is LogError.
+	ErrorLevel LogLevel
+	// PanicLevel is the level of "goroutine panicked" record (with the stack). Default is LogError.
+	PanicLevel LogLevel
+	// SlowLevel is the level of "goroutine slow" record, which is logged in addition to the completion record,
+	// if the goroutine has been running for SlowThreshold or longer. Default is LogWarn.
+	SlowLevel LogLevel
+	// SlowThreshold -- see SlowLevel. Zero disables "goroutine slow" records.
+	SlowThreshold time.Duration
+
+	// FnAttrs (optional) extracts attributes (alternating keys and values) from the context,
+	// e.g. request ID or trace ID. They are added to each record.
+	FnAttrs func(ctx context.Context) []any
+
+	// SampleEvery makes start and success records logged for every N-th execution only
+	// (e.g. for noisy high-volume batches). Failures, panics and slow completions are always logged.
+	// Zero means all executions are logged.
+	SampleEvery int
+}
+
+// MwLog creates a middleware that logs structured records about the goroutine execution:
+// start, success, error, panic (re-panicked after logging) and slow completion.
+//
+// Each record contains attributes of GoroutineInfo (if available -- i.e. the goroutine is executed by a Batch),
+// attributes extracted via LogConfig.FnAttrs and, for completion records, the duration.
+//
+// Panics if:
+//   - `cfg.Logger` is nil
+//   - any level is unknown
+//   - `cfg.SlowThreshold` < 0
+//   - `cfg.SampleEvery` < 0
+func MwLog(cfg LogConfig) Middleware {
+	if cfg.Logger == nil {
+		panic("`cfg.Logger` must not be `nil`")
+	}
+
+	levels := []*LogLevel{&cfg.StartLevel, &cfg.SuccessLevel, &cfg.ErrorLevel, &cfg.PanicLevel, &cfg.SlowLevel}
+	defaults := []LogLevel{LogDebug, LogDebug, LogError, LogError, LogWarn}
+
+	for i, level := range levels {
+		if *level < LogDefault || *level > LogOff {
+			panic("`cfg` levels must be known")
+		}
+
+		if *level == LogDefault {
+			*level = defaults[i]
+		}
+	}
+
+	if cfg.SlowThreshold < 0 {
+		panic("`cfg.SlowThreshold` must not be negative")
+	}
+
+	if cfg.SampleEvery < 0 {
+		panic("`cfg.SampleEvery` must not be negative")
+	}
+
+	var executions uint64
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) (rErr error) {
+			sampled := cfg.SampleEvery <= 1 || (atomic.AddUint64(&executions, 1)-1)%uint64(cfg.SampleEvery) == 0
+
+			attrs := logAttrs(ctx, cfg.FnAttrs)
+
+			if sampled {
+				logRecord(ctx, cfg.Logger, cfg.StartLevel, "goroutine started", attrs)
+			}
+
+			startedAt := time.Now()
+
+			defer func() {
+				duration := time.Since(startedAt)
+				completionAttrs := append(attrs[:len(attrs):len(attrs)], "duration", duration)
+
+				if pv := recover(); pv != nil {
+					logRecord(ctx, cfg.Logger, cfg.PanicLevel, "goroutine panicked",
+						append(completionAttrs, "panic", pv, "stack", string(debug.Stack())))
+					panic(pv)
+				}
+
+				if rErr != nil {
+					logRecord(ctx, cfg.Logger, cfg.ErrorLevel, "goroutine failed", append(completionAttrs, "error", rErr))
+				} else if sampled {
+					logRecord(ctx, cfg.Logger, cfg.SuccessLevel, "goroutine succeeded", completionAttrs)
+				}
+
+				if cfg.SlowThreshold > 0 && duration >= cfg.SlowThreshold {
+					logRecord(ctx, cfg.Logger, cfg.SlowLevel, "goroutine slow",
+						append(completionAttrs, "threshold", cfg.SlowThreshold))
+				}
+			}()
+
+			return g(ctx)
+		}
+	}
+}
+
+// logAttrs returns attributes of GoroutineInfo and attributes extracted via `fnAttrs`.
+func logAttrs(ctx context.Context, fnAttrs func(ctx context.Context) []any) []any {
+	attrs := make([]any, 0)
+
+	if info, ok := InfoFromContext(ctx); ok {
+		attrs = append(attrs, "goroutine.index", info.Index)
+		if info.Name != "" {
+			attrs = append(attrs, "goroutine.name", info.Name)
+		}
+		if len(info.Labels) > 0 {
+			attrs = append(attrs, "goroutine.labels", info.Labels)
+		}
+		attrs = append(attrs, "batch.id", info.BatchID, "batch.strategy", info.Strategy)
+	}
+
+	if fnAttrs != nil {
+		attrs = append(attrs, fnAttrs(ctx)...)
+	}
+
+	return attrs
+}
+
+// logRecord logs the record with the `level` via the `logger`.
+func logRecord(ctx context.Context, logger Logger, level LogLevel, msg string, attrs []any) {
+	switch level {
+	case LogDebug:
+		logger.DebugContext(ctx, msg, attrs...)
+	case LogInfo:
+		logger.InfoContext(ctx, msg, attrs...)
+	case LogWarn:
+		logger.WarnContext(ctx, msg, attrs...)
+	case LogError:
+		logger.ErrorContext(ctx, msg, attrs...)
+	}
+}
@@ -0,0 +1,202 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// RateLimitPolicy defines MwRateLimit behaviour, when no token is available.
+type RateLimitPolicy int
+
+const (
+	// RateLimitWait -- waits for the token (or until the context is done).
+	RateLimitWait RateLimitPolicy = iota
+	// RateLimitFailFast -- returns ErrRateLimited immediately.
+	RateLimitFailFast
+)
+
+// ErrRateLimited is returned by MwRateLimit with RateLimitFailFast policy instead of executing the goroutine,
+// when no token is available.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimiterConfig configures the RateLimiter.
+type RateLimiterConfig struct {
+	// Rate is the number of tokens added to the bucket per second.
+	Rate float64
+	// Burst is the bucket capacity, i.e. the maximum number of goroutines started at once.
+	// The bucket is full initially.
+	Burst int
+}
+
+// RateLimiterStats is a snapshot of the RateLimiter state and counters.
+type RateLimiterStats struct {
+	// Tokens currently available in the bucket.
+	// Negative, when tokens are already reserved by waiting goroutines.
+	Tokens float64
+	// Waiting is the number of goroutines waiting for tokens.
+	Waiting int
+
+	// Allowed is the number of goroutines got tokens (immediately or after waiting).
+	Allowed uint64
+	// Rejected is the number of goroutines rejected with ErrRateLimited.
+	Rejected uint64
+	// Cancelled is the number of goroutines, which context was done while waiting for tokens.
+	Cancelled uint64
+	// TotalWait is the total time spent by allowed goroutines waiting for tokens.
+	TotalWait time.Duration
+}
+
+// RateLimiter is a token bucket shared by all goroutines wrapped with the same instance
+// via MwRateLimit (even from different batches and Goroutiner instances).
+//
+// Tokens are reserved in the order of requests, so waiting goroutines are started in FIFO order.
+//
+// Thread-safe.
+type RateLimiter struct {
+	cfg RateLimiterConfig
+
+	mu        sync.Mutex
+	tokens    float64
+	updatedAt time.Time
+	stats     RateLimiterStats
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// NewRateLimiter
+//
+// Panics if:
+//   - `cfg.Rate` <= 0
+//   - `cfg.Burst` <= 0
+func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
+	if cfg.Rate <= 0 {
+		panic("`cfg.Rate` must be greater than zero")
+	}
+
+	if cfg.Burst <= 0 {
+		panic("`cfg.Burst` must be greater than zero")
+	}
+
+	return &RateLimiter{
+		cfg:       cfg,
+		tokens:    float64(cfg.Burst),
+		updatedAt: time.Now(),
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Stats returns the current state and counters of the limiter.
+func (rl *RateLimiter) Stats() RateLimiterStats {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill(time.Now())
+
+	stats := rl.stats
+	stats.Tokens = rl.tokens
+
+	return stats
+}
+
+// wait takes a token, waiting for it according to the `policy`.
+func (rl *RateLimiter) wait(ctx context.Context, policy RateLimitPolicy) error {
+	rl.mu.Lock()
+
+	rl.refill(time.Now())
+
+	if rl.tokens >= 1 {
+		rl.tokens--
+		rl.stats.Allowed++
+		rl.mu.Unlock()
+		return nil
+	}
+
+	if policy == RateLimitFailFast {
+		rl.stats.Rejected++
+		rl.mu.Unlock()
+		return ErrRateLimited
+	}
+
+	// reserves the token in advance, so later goroutines wait for later tokens.
+	delay := time.Duration((1 - rl.tokens) / rl.cfg.Rate * float64(time.Second))
+	rl.tokens--
+	rl.stats.Waiting++
+	rl.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		rl.mu.Lock()
+		// returns the reserved token.
+		rl.tokens++
+		rl.stats.Waiting--
+		rl.stats.Cancelled++
+		rl.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		rl.mu.Lock()
+		rl.stats.Waiting--
+		rl.stats.Allowed++
+		rl.stats.TotalWait += delay
+		rl.mu.Unlock()
+		return nil
+	}
+}
+
+// refill adds tokens for the time passed since the last update. Must be called under the lock.
+func (rl *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(rl.updatedAt); elapsed > 0 {
+		rl.tokens += elapsed.Seconds() * rl.cfg.Rate
+		rl.updatedAt = now
+	}
+
+	if burst := float64(rl.cfg.Burst); rl.tokens > burst {
+		rl.tokens = burst
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Middleware
+// ---------------------------------------------------------------------------------------------------------------------
+
+// MwRateLimit creates a middleware that takes a token from the `rl` before executing the goroutine.
+// If no token is available, the goroutine either waits for it (returning the context error, if the context is done
+// first), or is not executed with ErrRateLimited -- depending on the `policy`.
+//
+// Panics if:
+//   - `rl` is nil
+//   - `policy` is unknown
+func MwRateLimit(rl *RateLimiter, policy RateLimitPolicy) Middleware {
+	if rl == nil {
+		panic("`rl` must not be `nil`")
+	}
+
+	if policy != RateLimitWait && policy != RateLimitFailFast {
+		panic("`policy` is unknown")
+	}
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) error {
+			if err := rl.wait(ctx, policy); err != nil {
+				return err
+			}
+
+			return g(ctx)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
@@ -0,0 +1,215 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryConfig configures MwRetry.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// Zero means no limit (then MaxElapsed must be set).
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the delay after each attempt (exponential backoff).
+	// Zero means 2; other values must be >= 1 (1 means constant backoff).
+	Multiplier float64
+	// Jitter randomizes each delay within [delay * (1 - Jitter), delay * (1 + Jitter)].
+	// Must be within [0, 1]. Zero means no jitter.
+	Jitter float64
+
+	// MaxElapsed limits the time since the first attempt start:
+	// no new attempt is started, if it would start after MaxElapsed.
+	// Zero means no limit (then MaxAttempts must be set).
+	MaxElapsed time.Duration
+
+	// FnRetryable decides, whether the error is worth retrying.
+	// Nil means all errors are retryable.
+	FnRetryable func(err error) bool
+	// FnOnRetry (optional) is called before waiting for the next attempt.
+	FnOnRetry func(attempt int, err error, delay time.Duration, ctx context.Context)
+}
+
+// RetryError is returned by MwRetry, when the goroutine has not succeeded.
+type RetryError struct {
+	// Errors of all made attempts in order.
+	Errors []error
+	// Interrupted is the context error, if retrying was interrupted by the context.
+	Interrupted error
+}
+
+func (e *RetryError) Error() string {
+	sb := new(strings.Builder)
+
+	if e.Interrupted != nil {
+		_, _ = fmt.Fprintf(sb, "retrying interrupted after %d attempt(s): %v", len(e.Errors), e.Interrupted)
+	} else {
+		_, _ = fmt.Fprintf(sb, "%d attempt(s) failed", len(e.Errors))
+	}
+
+	for i, err := range e.Errors {
+		_, _ = fmt.Fprintf(sb, "\n  attempt #%d: %v", i+1, err)
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the context error, if retrying was interrupted, or the error of the last attempt.
+func (e *RetryError) Unwrap() error {
+	if e.Interrupted != nil {
+		return e.Interrupted
+	}
+
+	if len(e.Errors) == 0 {
+		return nil
+	}
+
+	return e.Errors[len(e.Errors)-1]
+}
+
+// Is reports whether any attempt error (or the context error) matches the `target`.
+func (e *RetryError) Is(target error) bool {
+	if e.Interrupted != nil && errors.Is(e.Interrupted, target) {
+		return true
+	}
+
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the latest attempt error (or the context error) matching the `target`.
+func (e *RetryError) As(target any) bool {
+	if e.Interrupted != nil && errors.As(e.Interrupted, target) {
+		return true
+	}
+
+	for i := len(e.Errors) - 1; i >= 0; i-- {
+		if errors.As(e.Errors[i], target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MwRetry creates a middleware that re-runs the goroutine while it fails with retryable errors,
+// waiting between attempts with exponential backoff and jitter.
+// Waiting is aborted, once the context is done.
+//
+// Returns *RetryError with errors of all attempts, if the goroutine has not succeeded.
+//
+// Panics if:
+//   - `cfg.MaxAttempts` < 0
+//   - `cfg.InitialBackoff`, `cfg.MaxBackoff` or `cfg.MaxElapsed` is negative
+//   - `cfg.Multiplier` is not 0 and < 1
+//   - `cfg.Jitter` is not within [0, 1]
+//   - both `cfg.MaxAttempts` and `cfg.MaxElapsed` are zero
+func MwRetry(cfg RetryConfig) Middleware {
+	if cfg.MaxAttempts < 0 {
+		panic("`cfg.MaxAttempts` must not be negative")
+	}
+
+	if cfg.InitialBackoff < 0 || cfg.MaxBackoff < 0 || cfg.MaxElapsed < 0 {
+		panic("`cfg.InitialBackoff`, `cfg.MaxBackoff` and `cfg.MaxElapsed` must not be negative")
+	}
+
+	if cfg.Multiplier == 0 {
+		cfg.Multiplier = 2
+	} else if cfg.Multiplier < 1 {
+		panic("`cfg.Multiplier` must be zero or >= 1")
+	}
+
+	if cfg.Jitter < 0 || cfg.Jitter > 1 {
+		panic("`cfg.Jitter` must be within [0, 1]")
+	}
+
+	if cfg.MaxAttempts == 0 && cfg.MaxElapsed == 0 {
+		panic("either `cfg.MaxAttempts` or `cfg.MaxElapsed` must be set")
+	}
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) error {
+			start := time.Now()
+			errs := make([]error, 0, cfg.MaxAttempts)
+			backoff := cfg.InitialBackoff
+
+			for attempt := 1; ; attempt++ {
+				err := g(ctx)
+				if err == nil {
+					return nil
+				}
+
+				errs = append(errs, err)
+
+				if cfg.FnRetryable != nil && !cfg.FnRetryable(err) {
+					return &RetryError{Errors: errs}
+				}
+
+				if cfg.MaxAttempts > 0 && attempt >= cfg.MaxAttempts {
+					return &RetryError{Errors: errs}
+				}
+
+				delay := cfg.delay(backoff)
+
+				if cfg.MaxElapsed > 0 && time.Since(start)+delay > cfg.MaxElapsed {
+					return &RetryError{Errors: errs}
+				}
+
+				if ctx.Err() != nil {
+					return &RetryError{Errors: errs, Interrupted: ctx.Err()}
+				}
+
+				if cfg.FnOnRetry != nil {
+					cfg.FnOnRetry(attempt, err, delay, ctx)
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return &RetryError{Errors: errs, Interrupted: ctx.Err()}
+				case <-timer.C:
+				}
+
+				backoff = cfg.next(backoff)
+			}
+		}
+	}
+}
+
+// delay returns the `backoff` with applied jitter and cap.
+func (cfg *RetryConfig) delay(backoff time.Duration) time.Duration {
+	if cfg.Jitter > 0 {
+		backoff = time.Duration(float64(backoff) * (1 - cfg.Jitter + 2*cfg.Jitter*rand.Float64()))
+	}
+
+	if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
+	}
+
+	return backoff
+}
+
+// next returns the backoff for the next attempt.
+func (cfg *RetryConfig) next(backoff time.Duration) time.Duration {
+	backoff = time.Duration(float64(backoff) * cfg.Multiplier)
+
+	if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,237 @@
+package goroutiner
+
+import (
+	"context"
+	"sync"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// SingleflightCancelPolicy defines, which context is used for the shared execution of the Singleflight.
+type SingleflightCancelPolicy int
+
+const (
+	// SingleflightCancelWithLeader -- the shared execution receives the context of the first caller (leader)
+	// and runs in its goroutine, so the leader cancellation fails the execution for all callers.
+	SingleflightCancelWithLeader SingleflightCancelPolicy = iota
+	// SingleflightCancelWithAll -- the shared execution receives the context with values of the leader context,
+	// but it is cancelled only once contexts of all callers are done.
+	// The execution runs in a separate goroutine, so callers can stop waiting independently.
+	SingleflightCancelWithAll
+)
+
+// SingleflightStats is a snapshot of the Singleflight counters.
+type SingleflightStats struct {
+	// InFlight is the number of keys being executed.
+	InFlight int
+	// Executions is the number of started shared executions.
+	Executions uint64
+	// Shared is the number of callers, which joined already running executions instead of starting new ones.
+	Shared uint64
+}
+
+// Singleflight deduplicates concurrent executions of goroutines with the same key via MwSingleflight:
+// only one goroutine runs, and all callers with the same key receive its error
+// (even from different batches and Goroutiner instances).
+//
+// Thread-safe.
+type Singleflight struct {
+	policy SingleflightCancelPolicy
+
+	mu    sync.Mutex
+	calls map[string]*singleflightCall
+	stats SingleflightStats
+}
+
+type singleflightCall struct {
+	done       chan struct{}
+	err        error
+	panicValue any
+	// waiters and cancel are used by SingleflightCancelWithAll policy only.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// NewSingleflight
+//
+// Panics if `policy` is unknown.
+func NewSingleflight(policy SingleflightCancelPolicy) *Singleflight {
+	if policy != SingleflightCancelWithLeader && policy != SingleflightCancelWithAll {
+		panic("`policy` is unknown")
+	}
+
+	return &Singleflight{
+		policy: policy,
+		calls:  make(map[string]*singleflightCall),
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Forget makes the next caller with the `key` start a new execution, even if the current one is still running.
+// Callers of the current execution still receive its error.
+func (sf *Singleflight) Forget(key string) {
+	sf.mu.Lock()
+	delete(sf.calls, key)
+	sf.mu.Unlock()
+}
+
+// Stats returns the current counters of the singleflight.
+func (sf *Singleflight) Stats() SingleflightStats {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+
+	stats := sf.stats
+	stats.InFlight = len(sf.calls)
+
+	return stats
+}
+
+// do executes the goroutine `g`, or joins the running execution with the same `key`.
+func (sf *Singleflight) do(ctx context.Context, key string, g Goroutine) error {
+	sf.mu.Lock()
+
+	if c, ok := sf.calls[key]; ok {
+		c.waiters++
+		sf.stats.Shared++
+		sf.mu.Unlock()
+
+		return sf.wait(ctx, key, c)
+	}
+
+	c := &singleflightCall{
+		done:    make(chan struct{}),
+		waiters: 1,
+	}
+	sf.calls[key] = c
+	sf.stats.Executions++
+	sf.mu.Unlock()
+
+	if sf.policy == SingleflightCancelWithLeader {
+		sf.execute(ctx, key, c, g)
+
+		if c.panicValue != nil {
+			panic(c.panicValue)
+		}
+
+		return c.err
+	}
+
+	execCtx, cancel := context.WithCancel(detachedContext{parent: ctx})
+	c.cancel = cancel
+
+	go func() {
+		defer cancel()
+		sf.execute(execCtx, key, c, g)
+	}()
+
+	return sf.wait(ctx, key, c)
+}
+
+// execute runs the goroutine `g` and publishes its result (including the panic) to callers.
+func (sf *Singleflight) execute(ctx context.Context, key string, c *singleflightCall, g Goroutine) {
+	defer func() {
+		if pv := recover(); pv != nil {
+			c.panicValue = pv
+		}
+
+		sf.mu.Lock()
+		// the key may be forgotten, and even used by a new execution.
+		if sf.calls[key] == c {
+			delete(sf.calls, key)
+		}
+		sf.mu.Unlock()
+
+		close(c.done)
+	}()
+
+	c.err = g(ctx)
+}
+
+// wait waits for the execution result, or until the `ctx` is done.
+// Panics of the execution are propagated to all callers.
+func (sf *Singleflight) wait(ctx context.Context, key string, c *singleflightCall) error {
+	select {
+	case <-c.done:
+		if c.panicValue != nil {
+			panic(c.panicValue)
+		}
+
+		return c.err
+	case <-ctx.Done():
+		if sf.policy == SingleflightCancelWithAll {
+			sf.mu.Lock()
+			c.waiters--
+			if c.waiters == 0 {
+				c.cancel()
+				// the cancelled execution may still be running -- the next caller must start a new one.
+				if sf.calls[key] == c {
+					delete(sf.calls, key)
+				}
+			}
+			sf.mu.Unlock()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Middleware
+// ---------------------------------------------------------------------------------------------------------------------
+
+// MwSingleflight creates a middleware that deduplicates concurrent executions of goroutines
+// with the same key returned by `fnKey` (e.g. taken from the context) -- see Singleflight.
+// Goroutines with an empty key are executed without deduplication.
+//
+// Note: callers, which joined the running execution, stop waiting once their context is done,
+// and return the context error.
+//
+// Panics if:
+//   - `sf` is nil
+//   - `fnKey` is nil
+func MwSingleflight(sf *Singleflight, fnKey func(ctx context.Context) string) Middleware {
+	if sf == nil {
+		panic("`sf` must not be `nil`")
+	}
+
+	if fnKey == nil {
+		panic("`fnKey` must not be `nil`")
+	}
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) error {
+			key := fnKey(ctx)
+			if key == "" {
+				return g(ctx)
+			}
+
+			return sf.do(ctx, key, g)
+		}
+	}
+}
+
+// MwSingleflightKey is the same as MwSingleflight, but with the static `key`.
+//
+// Panics if:
+//   - `sf` is nil
+//   - `key` is empty
+func MwSingleflightKey(sf *Singleflight, key string) Middleware {
+	if key == "" {
+		panic("`key` must not be empty")
+	}
+
+	return MwSingleflight(sf, func(ctx context.Context) string {
+		return key
+	})
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
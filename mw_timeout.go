@@ -0,0 +1,142 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeoutPolicy defines MwTimeout behaviour for goroutines, which ignore the context and do not return in time.
+type TimeoutPolicy int
+
+const (
+	// TimeoutWait -- waits for the goroutine to return even after the timeout.
+	TimeoutWait TimeoutPolicy = iota
+	// TimeoutAbandon -- returns immediately once the timeout is reached,
+	// while the goroutine keeps running in background, and its result is discarded.
+	TimeoutAbandon
+)
+
+// ErrTimeout is matched (via errors.Is) by *TimeoutError.
+var ErrTimeout = errors.New("goroutine timeout")
+
+// TimeoutError is returned by MwTimeout, when the goroutine has not completed in time.
+type TimeoutError struct {
+	Timeout time.Duration
+	// Err is the error returned by the goroutine (only for TimeoutWait policy).
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%v (%s): %v", ErrTimeout, e.Timeout, e.Err)
+	}
+
+	return fmt.Sprintf("%v (%s)", ErrTimeout, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// MwTimeout creates a middleware that runs the goroutine with a context cancelled after `d`.
+// Returns *TimeoutError (matching ErrTimeout), if the timeout is reached,
+// even if the goroutine ignores the context and returns later (see TimeoutPolicy).
+//
+// Note: with TimeoutAbandon policy, the goroutine is executed in a separate goroutine.
+// Its panics are propagated to the caller while not abandoned, and crash the process after that.
+//
+// Panics if:
+//   - `d` <= 0
+//   - `policy` is unknown
+func MwTimeout(d time.Duration, policy TimeoutPolicy) Middleware {
+	if d <= 0 {
+		panic("`d` must be greater than zero")
+	}
+
+	if policy != TimeoutWait && policy != TimeoutAbandon {
+		panic("`policy` is unknown")
+	}
+
+	return func(g Goroutine) Goroutine {
+		return func(ctx context.Context) error {
+			tCtx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			// the timeout is reached, only if the parent context is not done,
+			// otherwise it's not the timeout of this middleware.
+			isTimeout := func() bool {
+				return errors.Is(tCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+			}
+
+			if policy == TimeoutWait {
+				err := g(tCtx)
+				if isTimeout() {
+					return &TimeoutError{Timeout: d, Err: err}
+				}
+				return err
+			}
+
+			type Result = struct {
+				err        error
+				panicValue any
+			}
+
+			resCh := make(chan Result, 1)
+			mu := new(sync.Mutex)
+			abandoned := false
+
+			go func() {
+				defer func() {
+					if pv := recover(); pv != nil {
+						mu.Lock()
+						defer mu.Unlock()
+						if abandoned {
+							panic(pv)
+						}
+						resCh <- Result{panicValue: pv}
+					}
+				}()
+
+				resCh <- Result{err: g(tCtx)}
+			}()
+
+			var res Result
+
+			select {
+			case res = <-resCh:
+			case <-tCtx.Done():
+				mu.Lock()
+				select {
+				case res = <-resCh:
+				default:
+					abandoned = true
+				}
+				mu.Unlock()
+
+				if abandoned {
+					if isTimeout() {
+						return &TimeoutError{Timeout: d}
+					}
+					return ctx.Err()
+				}
+			}
+
+			if res.panicValue != nil {
+				panic(res.panicValue)
+			}
+
+			if isTimeout() {
+				return &TimeoutError{Timeout: d, Err: res.err}
+			}
+
+			return res.err
+		}
+	}
+}
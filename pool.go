@@ -0,0 +1,405 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+var (
+	// ErrPoolClosed is returned on submitting to the Pool after Pool.Shutdown.
+	ErrPoolClosed = errors.New("pool is closed")
+	// ErrPoolFull is returned by Pool.TrySubmit, when the queue is full.
+	ErrPoolFull = errors.New("pool queue is full")
+)
+
+// PoolConfig configures the Pool.
+type PoolConfig struct {
+	// Workers is the maximum number of workers. Can be changed later via Pool.Resize.
+	Workers int
+	// MinWorkers is the number of workers started immediately and never reaped as idle.
+	MinWorkers int
+	// QueueSize limits the number of queued (not running yet) goroutines.
+	// Zero means unbounded queue.
+	QueueSize int
+	// IdleTimeout is the time after which an idle worker exits (keeping at least MinWorkers).
+	// Zero means workers are never reaped.
+	IdleTimeout time.Duration
+}
+
+// PoolStats is a snapshot of the Pool counters.
+type PoolStats struct {
+	Workers   int
+	Idle      int
+	Queued    int
+	Running   int
+	Completed uint64
+	Failed    uint64
+}
+
+// Pool is a long-lived set of workers executing submitted goroutines.
+// Workers are started on demand (up to PoolConfig.Workers) and reused for next goroutines.
+//
+// Note: panics in goroutines are not recovered (as in the Batch) -- use MwPanicToError if needed.
+//
+// Thread-safe.
+type Pool struct {
+	cfg PoolConfig
+	mws []Middleware
+
+	mu      sync.Mutex
+	size    int
+	workers int
+	idle    int
+	running int
+	queue   []*poolTask
+	// changed is closed (and replaced) on each change, which waiting workers and submitters may be interested in.
+	changed   chan struct{}
+	closed    bool
+	stopped   chan struct{}
+	isStopped bool
+
+	completed uint64
+	failed    uint64
+}
+
+type poolTask struct {
+	ctx   context.Context
+	fn    Goroutine
+	errCh chan error
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Pool creates a new pool with optional pool middleware.
+// Pool middleware are applied to each goroutine after the innermost global middleware.
+// Middleware order: first = outermost.
+//
+// Panics if:
+//   - `cfg.Workers` <= 0
+//   - `cfg.MinWorkers` < 0 or > `cfg.Workers`
+//   - `cfg.QueueSize` < 0
+//   - `cfg.IdleTimeout` < 0
+//   - `mws` contains nil
+func (g *Goroutiner) Pool(cfg PoolConfig, mws ...Middleware) *Pool {
+	if cfg.Workers <= 0 {
+		panic("`cfg.Workers` must be greater than zero")
+	}
+
+	if cfg.MinWorkers < 0 || cfg.MinWorkers > cfg.Workers {
+		panic("`cfg.MinWorkers` must be within [0, `cfg.Workers`]")
+	}
+
+	if cfg.QueueSize < 0 {
+		panic("`cfg.QueueSize` must not be negative")
+	}
+
+	if cfg.IdleTimeout < 0 {
+		panic("`cfg.IdleTimeout` must not be negative")
+	}
+
+	for _, mw := range mws {
+		if mw == nil {
+			panic("`mws` must contain no `nil` elements")
+		}
+	}
+
+	poolMws := make([]Middleware, 0, len(g.globalMws)+len(mws))
+	poolMws = append(poolMws, g.globalMws...)
+	poolMws = append(poolMws, mws...)
+
+	p := &Pool{
+		cfg:     cfg,
+		mws:     poolMws,
+		size:    cfg.Workers,
+		queue:   make([]*poolTask, 0),
+		changed: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	p.mu.Lock()
+	for p.workers < cfg.MinWorkers {
+		p.spawn()
+	}
+	p.mu.Unlock()
+
+	return p
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Submit queues the goroutine with optional individual middleware for execution.
+// Individual middleware will be applied only to currently submitted goroutine after the most inner pool middleware.
+// Middleware order: first = outermost.
+//
+// The `ctx` is passed to the goroutine. If the `ctx` is done before the goroutine is started,
+// the goroutine is not executed, and the context error is used as its result.
+//
+// If the queue is full, waits for a free place (backpressure) until the `ctx` is done.
+//
+// Returns a channel (buffered, so it can be ignored) receiving the goroutine error.
+// Returns ErrPoolClosed after Pool.Shutdown, or the context error.
+//
+// Panics if:
+//   - `ctx` is nil
+//   - `fn` is nil
+//   - `mws` contains nil
+func (p *Pool) Submit(ctx context.Context, fn Goroutine, mws ...Middleware) (<-chan error, error) {
+	return p.submit(ctx, fn, mws, true)
+}
+
+// TrySubmit is the same as Pool.Submit, but returns ErrPoolFull instead of waiting, if the queue is full.
+func (p *Pool) TrySubmit(ctx context.Context, fn Goroutine, mws ...Middleware) (<-chan error, error) {
+	return p.submit(ctx, fn, mws, false)
+}
+
+func (p *Pool) submit(ctx context.Context, fn Goroutine, mws []Middleware, wait bool) (<-chan error, error) {
+	if ctx == nil {
+		panic("`ctx` must not be `nil`")
+	}
+
+	if fn == nil {
+		panic("`fn` must not be `nil`")
+	}
+
+	for _, mw := range mws {
+		if mw == nil {
+			panic("`mws` must contain no `nil` elements")
+		}
+	}
+
+	for j := len(mws) - 1; j >= 0; j-- {
+		fn = mws[j](fn)
+	}
+
+	for j := len(p.mws) - 1; j >= 0; j-- {
+		fn = p.mws[j](fn)
+	}
+
+	task := &poolTask{
+		ctx:   ctx,
+		fn:    fn,
+		errCh: make(chan error, 1),
+	}
+
+	p.mu.Lock()
+
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		if p.cfg.QueueSize == 0 || len(p.queue) < p.cfg.QueueSize {
+			break
+		}
+
+		if !wait {
+			p.mu.Unlock()
+			return nil, ErrPoolFull
+		}
+
+		changed := p.changed
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+
+		p.mu.Lock()
+	}
+
+	p.queue = append(p.queue, task)
+
+	// starts new workers, if queued goroutines can't be taken by idle ones.
+	for need := len(p.queue) - p.idle; need > 0 && p.workers < p.size; need-- {
+		p.spawn()
+	}
+
+	p.notify()
+	p.mu.Unlock()
+
+	return task.errCh, nil
+}
+
+// Resize changes the maximum number of workers.
+// Surplus workers exit after completing their current goroutines.
+//
+// Panics if `n` <= 0.
+func (p *Pool) Resize(n int) {
+	if n <= 0 {
+		panic("`n` must be greater than zero")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.size = n
+
+	for need := len(p.queue) - p.idle; need > 0 && p.workers < p.size; need-- {
+		p.spawn()
+	}
+
+	p.notify()
+}
+
+// Stats returns a snapshot of the Pool counters.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{
+		Workers:   p.workers,
+		Idle:      p.idle,
+		Queued:    len(p.queue),
+		Running:   p.running,
+		Completed: p.completed,
+		Failed:    p.failed,
+	}
+}
+
+// Shutdown stops accepting new goroutines and waits, until all queued and running goroutines are completed.
+// If the `ctx` is done before, returns the context error, while remaining goroutines keep executing in background.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.notify()
+	p.checkStopped()
+	p.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.stopped:
+		return nil
+	}
+}
+
+// Workers
+// ---------------------------------------------------------------------------------------------------------------------
+
+// spawn starts a new worker. Must be called under the lock.
+func (p *Pool) spawn() {
+	p.workers++
+	go p.work()
+}
+
+// notify wakes up all waiting workers and submitters. Must be called under the lock.
+func (p *Pool) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// checkStopped marks the Pool as stopped, once it's closed and all goroutines are completed.
+// Must be called under the lock.
+func (p *Pool) checkStopped() {
+	if p.closed && !p.isStopped && p.workers == 0 && len(p.queue) == 0 {
+		p.isStopped = true
+		close(p.stopped)
+	}
+}
+
+func (p *Pool) work() {
+	var idleTimer *time.Timer
+	if p.cfg.IdleTimeout > 0 {
+		idleTimer = time.NewTimer(p.cfg.IdleTimeout)
+		defer idleTimer.Stop()
+	}
+
+	p.mu.Lock()
+
+	for p.workers <= p.size {
+		if len(p.queue) > 0 {
+			task := p.queue[0]
+			p.queue[0] = nil
+			p.queue = p.queue[1:]
+			p.running++
+			p.notify()
+			p.mu.Unlock()
+
+			err := task.run()
+
+			p.mu.Lock()
+			p.running--
+			p.completed++
+			if err != nil {
+				p.failed++
+			}
+
+			continue
+		}
+
+		if p.closed {
+			break
+		}
+
+		changed := p.changed
+		p.idle++
+		p.mu.Unlock()
+
+		timedOut := false
+
+		if idleTimer != nil {
+			resetTimer(idleTimer, p.cfg.IdleTimeout)
+
+			select {
+			case <-changed:
+			case <-idleTimer.C:
+				timedOut = true
+			}
+		} else {
+			<-changed
+		}
+
+		p.mu.Lock()
+		p.idle--
+
+		if timedOut && len(p.queue) == 0 && p.workers > p.cfg.MinWorkers {
+			break
+		}
+	}
+
+	p.workers--
+	p.notify()
+	p.checkStopped()
+	p.mu.Unlock()
+}
+
+func (t *poolTask) run() error {
+	var err error
+
+	if ctxErr := t.ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	} else {
+		err = t.fn(t.ctx)
+	}
+
+	t.errCh <- err
+
+	return err
+}
+
+// resetTimer resets the timer, which may be fired or not.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+
+	t.Reset(d)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
@@ -0,0 +1,412 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// ErrCompensationFailed is matched (via errors.Is) by *SagaError, if any compensation has failed,
+// i.e. the saga has not been fully rolled back.
+var ErrCompensationFailed = errors.New("saga compensation failed")
+
+// SagaError is returned by Saga.Run, when any step has failed.
+type SagaError struct {
+	// StepErrors -- index `i` matches `i`-th added step:
+	// nil for completed steps, ErrSkipped for steps not started because of the failure.
+	StepErrors []error
+	// CompensationErrors -- index `i` matches `i`-th added step:
+	// non-nil only for completed steps, which compensation has failed.
+	CompensationErrors []error
+}
+
+func (e *SagaError) Error() string {
+	sb := new(strings.Builder)
+
+	failed, compensated := 0, 0
+	for i, err := range e.StepErrors {
+		switch {
+		case err == nil && e.CompensationErrors[i] == nil:
+			compensated++
+		case err != nil && !errors.Is(err, ErrSkipped):
+			failed++
+		}
+	}
+
+	_, _ = fmt.Fprintf(sb, "saga failed: %d step(s) failed, %d step(s) compensated", failed, compensated)
+
+	for i, err := range e.StepErrors {
+		if err != nil && !errors.Is(err, ErrSkipped) {
+			_, _ = fmt.Fprintf(sb, "\n  step #%d: %v", i, err)
+		}
+	}
+
+	for i, err := range e.CompensationErrors {
+		if err != nil {
+			_, _ = fmt.Fprintf(sb, "\n  compensation #%d: %v", i, err)
+		}
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the error of the first failed step.
+func (e *SagaError) Unwrap() error {
+	for _, err := range e.StepErrors {
+		if err != nil && !errors.Is(err, ErrSkipped) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Is reports whether any step or compensation error matches the `target`.
+// ErrCompensationFailed is matched, if any compensation has failed.
+func (e *SagaError) Is(target error) bool {
+	for _, err := range e.CompensationErrors {
+		if err != nil && target == ErrCompensationFailed {
+			return true
+		}
+	}
+
+	for _, errs := range [][]error{e.StepErrors, e.CompensationErrors} {
+		for _, err := range errs {
+			if err != nil && errors.Is(err, target) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// As finds the first step error (or then the first compensation error) matching the `target`.
+func (e *SagaError) As(target any) bool {
+	for _, errs := range [][]error{e.StepErrors, e.CompensationErrors} {
+		for _, err := range errs {
+			if err != nil && errors.As(err, target) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Saga executes steps (goroutines) paired with compensating goroutines:
+// once any step fails, the completed steps are undone by their compensations in the reverse order.
+//
+// Steps are executed sequentially, or in parallel groups (see Saga.Parallel).
+//
+// Not thread-safe, as there is no practical need to make it thread‑safe.
+type Saga struct {
+	// batch keeps the context, middleware, settings and step configs.
+	batch *Batch
+	// compensations -- index `i` matches `i`-th added step, nil means nothing to compensate.
+	compensations   []Goroutine
+	groups          []int
+	globalMws       []Middleware
+	compensationMws []Middleware
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Saga creates a new saga with the given context and optional saga middleware.
+// Saga middleware are applied to each step (but not compensation -- see Saga.CompensationMiddleware)
+// after the innermost global middleware.
+// Middleware order: first = outermost.
+//
+// Panics if:
+//   - `ctx` is nil
+//   - `mws` contains nil
+func (g *Goroutiner) Saga(ctx context.Context, mws ...Middleware) *Saga {
+	return &Saga{
+		batch:           g.Batch(ctx, mws...),
+		compensations:   make([]Goroutine, 0),
+		groups:          make([]int, 0),
+		globalMws:       g.globalMws,
+		compensationMws: g.globalMws,
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Step adds a new step, which is started after all previously added steps are completed.
+// `compensate` undoes the step, once any later step fails. Nil means nothing to compensate.
+// Individual middleware `mws` are applied to the step only -- see Batch.Add.
+//
+// Panics if:
+//   - `fn` is nil
+//   - `mws` contains nil
+func (s *Saga) Step(fn, compensate Goroutine, mws ...Middleware) *Saga {
+	s.batch.Add(fn, mws...)
+	s.compensations = append(s.compensations, compensate)
+
+	group := 0
+	if len(s.groups) > 0 {
+		group = s.groups[len(s.groups)-1] + 1
+	}
+	s.groups = append(s.groups, group)
+
+	return s
+}
+
+// Parallel adds a new step, which is executed concurrently with the previously added step -- see Saga.Step.
+// E.g. `Step(a, ...).Parallel(b, ...).Parallel(c, ...).Step(d, ...)` executes `a`, `b`, `c` concurrently,
+// and then `d`.
+//
+// Steps of the same group are awaited even if any of them fails, so all completed steps are compensated.
+//
+// Panics if:
+//   - no steps were added before
+//   - `fn` is nil
+//   - `mws` contains nil
+func (s *Saga) Parallel(fn, compensate Goroutine, mws ...Middleware) *Saga {
+	if len(s.groups) == 0 {
+		panic("at least one step must be added before")
+	}
+
+	s.batch.Add(fn, mws...)
+	s.compensations = append(s.compensations, compensate)
+	s.groups = append(s.groups, s.groups[len(s.groups)-1])
+
+	return s
+}
+
+// CompensationMiddleware sets middleware applied to each compensation after the innermost global middleware
+// (e.g. MwRetry, as compensations are expected to succeed eventually).
+// Middleware order: first = outermost.
+//
+// Panics if `mws` contains nil.
+func (s *Saga) CompensationMiddleware(mws ...Middleware) *Saga {
+	for _, mw := range mws {
+		if mw == nil {
+			panic("`mws` must contain no `nil` elements")
+		}
+	}
+
+	compensationMws := make([]Middleware, 0, len(s.globalMws)+len(mws))
+	compensationMws = append(compensationMws, s.globalMws...)
+	compensationMws = append(compensationMws, mws...)
+
+	s.compensationMws = compensationMws
+
+	return s
+}
+
+// Limit -- see Batch.Limit. Applied to steps of each parallel group and to their compensations.
+func (s *Saga) Limit(n int) *Saga {
+	s.batch.Limit(n)
+
+	return s
+}
+
+// Timeout -- see Batch.Timeout. Applied to steps only.
+func (s *Saga) Timeout(d time.Duration) *Saga {
+	s.batch.Timeout(d)
+
+	return s
+}
+
+// Deadline -- see Batch.Deadline. Applied to steps only.
+func (s *Saga) Deadline(t time.Time) *Saga {
+	s.batch.Deadline(t)
+
+	return s
+}
+
+// PropagatePanics -- see Batch.PropagatePanics.
+// Panicked steps and compensations are treated as failed, so the calling goroutine panics
+// after the whole compensation only (with the first panic of steps, or else of compensations).
+func (s *Saga) PropagatePanics() *Saga {
+	s.batch.PropagatePanics()
+
+	return s
+}
+
+// Executing
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Run executes steps group by group, until all steps are completed, or any step fails.
+// Steps of groups following the failed one are not started.
+// If the context is done before a group is started, the context error is used as results of its steps.
+//
+// Once any step fails, compensations of the completed steps are executed group by group in the reverse order
+// (compensations of the same group are executed concurrently).
+// Compensations receive the context without cancellation and deadline of the saga context
+// (but with its values), so they are executed even if the saga has failed because of the cancellation.
+//
+// Returns nil, if all steps are completed, or *SagaError otherwise.
+//
+// Panics if:
+//   - no steps were added to the Saga
+//   - any step or compensation panicked and Saga.PropagatePanics is enabled (with *GoroutinePanic)
+func (s *Saga) Run() error {
+	gs := s.batch.prepareGoroutines("Saga")
+	gs, fnRepanic := s.batch.catchPanics(gs)
+
+	stepErrs := s.runSteps(gs)
+
+	var sagaErr *SagaError
+	fnCompensationRepanic := func() {}
+
+	for _, err := range stepErrs {
+		if err != nil {
+			sagaErr = &SagaError{StepErrors: stepErrs}
+			sagaErr.CompensationErrors, fnCompensationRepanic = s.compensate(stepErrs)
+			break
+		}
+	}
+
+	fnRepanic()
+	fnCompensationRepanic()
+
+	if sagaErr == nil {
+		return nil
+	}
+
+	return sagaErr
+}
+
+// runSteps executes steps `gs` group by group and returns their errors.
+func (s *Saga) runSteps(gs []Goroutine) []error {
+	ctx, cancel := s.batch.executionContext()
+	defer cancel()
+
+	errs := make([]error, len(gs))
+	failed := false
+
+	for _, group := range s.groupIndexes(len(gs)) {
+		if failed {
+			for _, i := range group {
+				errs[i] = ErrSkipped
+			}
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			for _, i := range group {
+				errs[i] = err
+			}
+			failed = true
+			continue
+		}
+
+		groupGs := make([]Goroutine, len(group))
+		for j, i := range group {
+			groupGs[j] = gs[i]
+		}
+
+		s.batch.execute(ctx, groupGs, func(j int, err error) {
+			errs[group[j]] = err
+		})
+
+		for _, i := range group {
+			if errs[i] != nil {
+				failed = true
+			}
+		}
+	}
+
+	return errs
+}
+
+// compensate executes compensations of the completed steps group by group in the reverse order
+// and returns their errors.
+// Panics of compensations are caught (if the panic propagation is enabled), so the compensation is never interrupted.
+// Returned `fnRepanic` panics with the first caught panic (if any).
+func (s *Saga) compensate(stepErrs []error) (errs []error, fnRepanic func()) {
+	errs = make([]error, len(stepErrs))
+	fnRepanics := make([]func(), 0)
+
+	groups := s.groupIndexes(len(stepErrs))
+
+	for g := len(groups) - 1; g >= 0; g-- {
+		b := newBatch(detachedContext{parent: s.batch.ctx}, s.compensationMws).Limit(s.batch.limit)
+		if s.batch.propagatePanics {
+			b.PropagatePanics()
+		}
+
+		indexes := make([]int, 0, len(groups[g]))
+
+		for _, i := range groups[g] {
+			if stepErrs[i] == nil && s.compensations[i] != nil {
+				b.Add(s.compensations[i])
+				indexes = append(indexes, i)
+			}
+		}
+
+		if len(indexes) == 0 {
+			continue
+		}
+
+		// compensations are reported as goroutines of the saga with indexes of their steps.
+		gs := b.prepareGoroutinesAs("SagaCompensation", s.batch.id, indexes)
+
+		groupErrs, fnGroupRepanic := b.waitDeferringPanics(gs)
+		fnRepanics = append(fnRepanics, fnGroupRepanic)
+
+		for j, err := range groupErrs {
+			// the index of the step is more useful, than the index within the group.
+			if gp, ok := err.(*GoroutinePanic); ok && s.batch.propagatePanics {
+				gp.Index = indexes[j]
+			}
+
+			errs[indexes[j]] = err
+		}
+	}
+
+	return errs, func() {
+		for _, fn := range fnRepanics {
+			fn()
+		}
+	}
+}
+
+// groupIndexes returns indexes of steps of each group in the execution order.
+func (s *Saga) groupIndexes(n int) [][]int {
+	groups := make([][]int, 0)
+
+	for i := 0; i < n; i++ {
+		if i == 0 || s.groups[i] != s.groups[i-1] {
+			groups = append(groups, make([]int, 0, 1))
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+
+	return groups
+}
+
+// detachedContext keeps values of the parent context, but never is cancelled and has no deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
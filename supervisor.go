@@ -0,0 +1,457 @@
+package goroutiner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+// RestartStrategy defines, which children are restarted by the Supervisor, when a child fails.
+type RestartStrategy int
+
+const (
+	// OneForOne -- only the failed child is restarted.
+	OneForOne RestartStrategy = iota
+	// OneForAll -- all children are stopped and restarted.
+	OneForAll
+	// RestForOne -- the failed child and all children added after it are stopped and restarted.
+	RestForOne
+)
+
+// ErrRestartIntensity is returned by Supervisor.Run, when restart intensity limit is exceeded.
+var ErrRestartIntensity = errors.New("supervisor restart intensity exceeded")
+
+// SupervisorConfig configures the Supervisor.
+type SupervisorConfig struct {
+	Strategy RestartStrategy
+
+	// MaxRestarts is the maximum number of restarts within the Period.
+	// Once exceeded, all children are stopped, and Supervisor.Run returns ErrRestartIntensity.
+	// Zero means no limit.
+	MaxRestarts int
+	// Period for MaxRestarts. Required, if MaxRestarts is set.
+	Period time.Duration
+
+	// InitialBackoff is the delay before the first restart of a child.
+	// The delay is doubled for each next restart of the same child.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between restarts.
+	// Zero means 1 minute (or InitialBackoff, if it is greater).
+	MaxBackoff time.Duration
+	// BackoffResetAfter makes the delay start from InitialBackoff again,
+	// if the failed child has been running at least for this duration (i.e. it was healthy).
+	// Zero means Period, if MaxRestarts is set, or MaxBackoff otherwise.
+	BackoffResetAfter time.Duration
+
+	// FnOnRestart (optional) is called before restarting children because of the failed child `name`.
+	FnOnRestart func(name string, err error, ctx context.Context)
+}
+
+const defaultSupervisorMaxBackoff = time.Minute
+
+// ChildExit describes the final exit of the Supervisor child.
+type ChildExit struct {
+	Name string
+	// Err is the last error of the child:
+	// nil for the normal exit, context.Canceled for the child stopped by the Supervisor.
+	Err error
+	// Restarts is the number of restarts of the child.
+	Restarts int
+}
+
+// Supervisor keeps added goroutines (children) running for the whole lifetime of the context:
+// children failed with an error or a panic are restarted according to the RestartStrategy.
+// Children returned nil are considered completed and are not restarted.
+//
+// Panics of children are recovered and treated as errors (*GoroutinePanic).
+//
+// Not thread-safe, as there is no practical need to make it thread‑safe.
+type Supervisor struct {
+	cfg      SupervisorConfig
+	mws      []Middleware
+	children []*supervisorChild
+}
+
+type supervisorChild struct {
+	name string
+	fn   Goroutine
+	mws  []Middleware
+}
+
+// supervisorChildRun is a state of a child within one Supervisor.Run call.
+type supervisorChildRun struct {
+	fn         Goroutine
+	generation int
+	cancel     context.CancelFunc
+	exited     chan struct{}
+	running    bool
+	completed  bool
+	startedAt  time.Time
+	restarts   int
+	// backoffExp is the number of restarts since the last backoff reset (see SupervisorConfig.BackoffResetAfter).
+	backoffExp int
+	err        error
+}
+
+type supervisorChildEvent struct {
+	i          int
+	generation int
+	err        error
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Supervisor creates a new supervisor with optional supervisor middleware.
+// Supervisor middleware are applied to each child after the innermost global middleware.
+// Middleware order: first = outermost.
+//
+// Panics if:
+//   - `cfg.Strategy` is unknown
+//   - `cfg.MaxRestarts` < 0
+//   - `cfg.Period` <= 0, while `cfg.MaxRestarts` is set
+//   - `cfg.InitialBackoff` or `cfg.MaxBackoff` or `cfg.BackoffResetAfter` is negative
+//   - `mws` contains nil
+func (g *Goroutiner) Supervisor(cfg SupervisorConfig, mws ...Middleware) *Supervisor {
+	if cfg.Strategy != OneForOne && cfg.Strategy != OneForAll && cfg.Strategy != RestForOne {
+		panic("`cfg.Strategy` is unknown")
+	}
+
+	if cfg.MaxRestarts < 0 {
+		panic("`cfg.MaxRestarts` must not be negative")
+	}
+
+	if cfg.MaxRestarts > 0 && cfg.Period <= 0 {
+		panic("`cfg.Period` must be greater than zero, if `cfg.MaxRestarts` is set")
+	}
+
+	if cfg.InitialBackoff < 0 || cfg.MaxBackoff < 0 || cfg.BackoffResetAfter < 0 {
+		panic("`cfg.InitialBackoff`, `cfg.MaxBackoff` and `cfg.BackoffResetAfter` must not be negative")
+	}
+
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = defaultSupervisorMaxBackoff
+		if cfg.InitialBackoff > cfg.MaxBackoff {
+			cfg.MaxBackoff = cfg.InitialBackoff
+		}
+	}
+
+	if cfg.BackoffResetAfter == 0 {
+		cfg.BackoffResetAfter = cfg.MaxBackoff
+		if cfg.MaxRestarts > 0 {
+			cfg.BackoffResetAfter = cfg.Period
+		}
+	}
+
+	for _, mw := range mws {
+		if mw == nil {
+			panic("`mws` must contain no `nil` elements")
+		}
+	}
+
+	supervisorMws := make([]Middleware, 0, len(g.globalMws)+len(mws))
+	supervisorMws = append(supervisorMws, g.globalMws...)
+	supervisorMws = append(supervisorMws, mws...)
+
+	return &Supervisor{
+		cfg:      cfg,
+		mws:      supervisorMws,
+		children: make([]*supervisorChild, 0),
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Add adds a new child with the `name` (used for reporting only) and optional individual middleware.
+// Individual middleware will be applied only to currently added child after the most inner supervisor middleware.
+// Middleware order: first = outermost.
+//
+// Panics if:
+//   - `fn` is nil
+//   - `mws` contains nil
+func (s *Supervisor) Add(name string, fn Goroutine, mws ...Middleware) *Supervisor {
+	if fn == nil {
+		panic("`fn` must not be `nil`")
+	}
+
+	for _, mw := range mws {
+		if mw == nil {
+			panic("`mws` must contain no `nil` elements")
+		}
+	}
+
+	s.children = append(s.children, &supervisorChild{
+		name: name,
+		fn:   fn,
+		mws:  mws,
+	})
+
+	return s
+}
+
+// Run starts all children and supervises them until:
+//   - the context is done -- all children are stopped, returns nil error;
+//   - all children are completed (returned nil) -- returns nil error;
+//   - restart intensity is exceeded -- all children are stopped, returns ErrRestartIntensity.
+//
+// Always waits for all children to exit, and returns their final exits in the adding order.
+//
+// Panics if no children were added to the Supervisor.
+func (s *Supervisor) Run(ctx context.Context) ([]ChildExit, error) {
+	if len(s.children) == 0 {
+		panic("at least one child is required")
+	}
+
+	runs := make([]*supervisorChildRun, len(s.children))
+	for i, child := range s.children {
+		runs[i] = &supervisorChildRun{fn: s.prepare(i, child)}
+	}
+
+	// Each child has at most one running instance, which sends at most one event.
+	// Instances stopped by the Supervisor do not wait for their events to be received (see start),
+	// as such events are stale, and nobody may receive them anymore.
+	events := make(chan supervisorChildEvent, len(runs))
+
+	for i := range runs {
+		s.start(ctx, events, runs, i)
+	}
+
+	restartTimes := make([]time.Time, 0)
+	var rErr error
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+
+		case ev := <-events:
+			run := runs[ev.i]
+
+			// exits of children stopped by the Supervisor are handled already.
+			if ev.generation != run.generation {
+				continue
+			}
+
+			run.running = false
+			run.err = ev.err
+
+			if ev.err == nil {
+				run.completed = true
+
+				if s.allCompleted(runs) {
+					break loop
+				}
+
+				continue
+			}
+
+			if s.cfg.MaxRestarts > 0 {
+				now := time.Now()
+				restartTimes = s.recentRestarts(restartTimes, now)
+
+				if len(restartTimes) >= s.cfg.MaxRestarts {
+					rErr = fmt.Errorf("%w: child %q: %v", ErrRestartIntensity, s.children[ev.i].name, ev.err)
+					break loop
+				}
+
+				restartTimes = append(restartTimes, now)
+			}
+
+			if s.cfg.FnOnRestart != nil {
+				s.cfg.FnOnRestart(s.children[ev.i].name, ev.err, ctx)
+			}
+
+			restartSet := s.restartSet(runs, ev.i)
+
+			// stop in reverse order, start in the adding order -- as dependent children are usually added later.
+			for j := len(restartSet) - 1; j >= 0; j-- {
+				s.stop(runs[restartSet[j]])
+			}
+
+			if time.Since(run.startedAt) >= s.cfg.BackoffResetAfter {
+				run.backoffExp = 0
+			}
+
+			delay := s.backoff(run.backoffExp)
+			run.backoffExp++
+
+			if !s.sleep(ctx, delay) {
+				break loop
+			}
+
+			for _, j := range restartSet {
+				runs[j].restarts++
+				s.start(ctx, events, runs, j)
+			}
+		}
+	}
+
+	for j := len(runs) - 1; j >= 0; j-- {
+		s.stop(runs[j])
+	}
+
+	exits := make([]ChildExit, len(runs))
+	for i, run := range runs {
+		exits[i] = ChildExit{
+			Name:     s.children[i].name,
+			Err:      run.err,
+			Restarts: run.restarts,
+		}
+	}
+
+	return exits, rErr
+}
+
+// prepare applies middleware to the child and recovers its panics.
+func (s *Supervisor) prepare(i int, child *supervisorChild) Goroutine {
+	fn := child.fn
+
+	for j := len(child.mws) - 1; j >= 0; j-- {
+		fn = child.mws[j](fn)
+	}
+
+	for j := len(s.mws) - 1; j >= 0; j-- {
+		fn = s.mws[j](fn)
+	}
+
+	return func(ctx context.Context) (rErr error) {
+		defer func() {
+			if pv := recover(); pv != nil {
+				rErr = &GoroutinePanic{Index: i, Value: pv, Stack: debug.Stack()}
+			}
+		}()
+
+		return fn(ctx)
+	}
+}
+
+func (s *Supervisor) start(ctx context.Context, events chan<- supervisorChildEvent, runs []*supervisorChildRun, i int) {
+	run := runs[i]
+
+	childCtx, cancel := context.WithCancel(ctx)
+
+	run.generation++
+	run.cancel = cancel
+	run.exited = make(chan struct{})
+	run.running = true
+	run.completed = false
+	run.startedAt = time.Now()
+	run.err = nil
+
+	go func(fn Goroutine, generation int, exited chan<- struct{}) {
+		defer close(exited)
+		defer cancel()
+
+		ev := supervisorChildEvent{i: i, generation: generation, err: fn(childCtx)}
+
+		select {
+		case events <- ev:
+		case <-childCtx.Done():
+			// stopped by the Supervisor (or the context is done) -- the exit is handled by the stop.
+		}
+	}(run.fn, run.generation, run.exited)
+}
+
+// stop cancels the running child and waits for its exit.
+func (s *Supervisor) stop(run *supervisorChildRun) {
+	if !run.running {
+		return
+	}
+
+	run.cancel()
+	<-run.exited
+
+	// the exit event becomes stale, and the cancellation is the exit reason.
+	run.generation++
+	run.running = false
+	run.err = context.Canceled
+}
+
+func (s *Supervisor) allCompleted(runs []*supervisorChildRun) bool {
+	for _, run := range runs {
+		if !run.completed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// restartSet returns indexes of children to restart because of the failed `i`-th child.
+func (s *Supervisor) restartSet(runs []*supervisorChildRun, i int) []int {
+	set := make([]int, 0, len(runs))
+
+	for j, run := range runs {
+		switch {
+		case j == i:
+			set = append(set, j)
+		case run.completed:
+			// completed children are not restarted
+		case s.cfg.Strategy == OneForAll:
+			set = append(set, j)
+		case s.cfg.Strategy == RestForOne && j > i:
+			set = append(set, j)
+		}
+	}
+
+	return set
+}
+
+func (s *Supervisor) recentRestarts(restartTimes []time.Time, now time.Time) []time.Time {
+	since := now.Add(-s.cfg.Period)
+
+	for len(restartTimes) > 0 && !restartTimes[0].After(since) {
+		restartTimes = restartTimes[1:]
+	}
+
+	return restartTimes
+}
+
+// backoff returns the delay before the restart of the child with `exp` restarts since the last backoff reset.
+// The delay saturates at MaxBackoff, so it never overflows.
+func (s *Supervisor) backoff(exp int) time.Duration {
+	delay := s.cfg.InitialBackoff
+
+	for k := 0; k < exp && delay > 0 && delay < s.cfg.MaxBackoff; k++ {
+		if delay > s.cfg.MaxBackoff/2 {
+			return s.cfg.MaxBackoff
+		}
+
+		delay *= 2
+	}
+
+	if delay > s.cfg.MaxBackoff {
+		delay = s.cfg.MaxBackoff
+	}
+
+	return delay
+}
+
+// sleep waits for the `d` and reports, whether the context is still not done.
+func (s *Supervisor) sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
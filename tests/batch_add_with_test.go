@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Batch_AddWith(t *testing.T) {
+	type G = goroutiner.Goroutine
+
+	ctx := context.TODO()
+	mw := func(g G) G { return g }
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.New().Batch(ctx).AddWith(g)
+			goroutiner.New().Batch(ctx).
+				AddWith(
+					g,
+					goroutiner.WithName("a"),
+					goroutiner.WithLabels(map[string]string{"k": "v"}),
+					goroutiner.WithMiddleware(mw, mw),
+					goroutiner.WithTimeout(time.Second),
+					goroutiner.WithRetry(goroutiner.RetryConfig{MaxAttempts: 2}),
+					goroutiner.WithOptional(),
+					goroutiner.WithStartDelay(0),
+				).
+				AddWith(g, goroutiner.WithName("b")).
+				AddNamed("c", g).
+				Wait()
+		})
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddWith(nil) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddWith(g, nil) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNamed("a", g).AddWith(g, goroutiner.WithName("a")) })
+		assert.Panics(t, func() { goroutiner.WithName("") })
+		assert.Panics(t, func() { goroutiner.WithMiddleware(mw, nil) })
+		assert.Panics(t, func() { goroutiner.WithTimeout(0) })
+		assert.Panics(t, func() { goroutiner.WithRetry(goroutiner.RetryConfig{}) })
+		assert.Panics(t, func() { goroutiner.WithStartDelay(-1) })
+	})
+
+	t.Run("options are visible to middleware", func(t *testing.T) {
+		var info goroutiner.GoroutineInfo
+
+		mwInfo := func(g G) G {
+			return func(ctx context.Context) error {
+				info, _ = goroutiner.InfoFromContext(ctx)
+				return g(ctx)
+			}
+		}
+
+		goroutiner.New(mwInfo).Batch(ctx).
+			AddWith(
+				g,
+				goroutiner.WithName("fetch"),
+				goroutiner.WithLabels(map[string]string{"a": "1", "b": "2"}),
+				goroutiner.WithLabels(map[string]string{"b": "3"}),
+				goroutiner.WithTimeout(time.Second),
+				goroutiner.WithOptional(),
+				goroutiner.WithStartDelay(time.Millisecond),
+			).
+			Wait()
+
+		assert.Equal(t, "fetch", info.Name)
+		assert.Equal(t, map[string]string{"a": "1", "b": "3"}, info.Labels)
+		assert.Equal(t, time.Second, info.Timeout)
+		assert.True(t, info.Optional)
+		assert.Equal(t, time.Millisecond, info.StartDelay)
+	})
+
+	t.Run("middleware order", func(t *testing.T) {
+		applied := make([]string, 0)
+		mMw := func(name string) goroutiner.Middleware {
+			return func(g G) G {
+				return func(ctx context.Context) error {
+					applied = append(applied, name)
+					return g(ctx)
+				}
+			}
+		}
+
+		goroutiner.New(mMw("global")).Batch(ctx, mMw("batch")).
+			AddWith(g, goroutiner.WithMiddleware(mMw("1")), goroutiner.WithMiddleware(mMw("2"), mMw("3"))).
+			Wait()
+
+		assert.Equal(t, []string{"global", "batch", "1", "2", "3"}, applied)
+	})
+
+	t.Run("timeout and retry", func(t *testing.T) {
+		var attempts int64
+
+		errs := goroutiner.New().Batch(ctx).
+			AddWith(
+				func(ctx context.Context) error {
+					if atomic.AddInt64(&attempts, 1) < 3 {
+						<-ctx.Done()
+						return ctx.Err()
+					}
+					return nil
+				},
+				goroutiner.WithTimeout(5*time.Millisecond),
+				goroutiner.WithRetry(goroutiner.RetryConfig{MaxAttempts: 3}),
+			).
+			AddWith(
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+				goroutiner.WithTimeout(5*time.Millisecond),
+			).
+			Wait()
+
+		assert.Equal(t, int64(3), attempts)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], goroutiner.ErrTimeout)
+	})
+
+	t.Run("optional goroutines do not cancel others", func(t *testing.T) {
+		errCritical := errors.New("critical")
+		completed := false
+
+		err := goroutiner.New().Batch(ctx).
+			AddWith(func(ctx context.Context) error { return errors.New("optional") }, goroutiner.WithOptional()).
+			Add(func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(10 * time.Millisecond):
+					completed = true
+					return nil
+				}
+			}).
+			CancelOnError()
+
+		assert.NoError(t, err)
+		assert.True(t, completed)
+
+		err = goroutiner.New().Batch(ctx).
+			AddWith(func(ctx context.Context) error { return errors.New("optional") }, goroutiner.WithOptional()).
+			Add(func(ctx context.Context) error { return errCritical }).
+			CancelOnError()
+
+		assert.Equal(t, errCritical, err)
+	})
+
+	t.Run("start delay", func(t *testing.T) {
+		var startedAt time.Time
+
+		before := time.Now()
+
+		errs := goroutiner.New().Batch(ctx).
+			AddWith(func(ctx context.Context) error {
+				startedAt = time.Now()
+				return nil
+			}, goroutiner.WithStartDelay(10*time.Millisecond)).
+			Wait()
+
+		assert.NoError(t, errs[0])
+		assert.GreaterOrEqual(t, startedAt.Sub(before), 10*time.Millisecond)
+
+		// context is done during the delay
+		executed := false
+
+		errs = goroutiner.New().Batch(ctx).Timeout(5*time.Millisecond).
+			AddWith(func(ctx context.Context) error {
+				executed = true
+				return nil
+			}, goroutiner.WithStartDelay(time.Second)).
+			Wait()
+
+		assert.False(t, executed)
+		assert.Equal(t, []error{context.DeadlineExceeded}, errs)
+	})
+
+	t.Run("names in results", func(t *testing.T) {
+		err := goroutiner.New().Batch(ctx).
+			AddWith(func(ctx context.Context) error { return errors.New("a failed") }, goroutiner.WithName("a")).
+			Add(func(ctx context.Context) error { return errors.New("b failed") }).
+			WaitErr()
+
+		var batchErr *goroutiner.BatchError
+		require.True(t, errors.As(err, &batchErr))
+		assert.Equal(t, []string{"a", ""}, batchErr.Names)
+		assert.EqualError(t, err, "2 of 2 goroutine(s) failed\n  goroutine #0 \"a\": a failed\n  goroutine #1: b failed")
+	})
+}
@@ -2,9 +2,11 @@ package tests
 
 import (
 	"context"
+	"errors"
 	goroutiner "github.com/selyukovn/go-routiner"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func Test_Batch_CancelOnError(t *testing.T) {
@@ -27,6 +29,70 @@ func Test_Batch_CancelOnError(t *testing.T) {
 
 	// Note: all added goroutines execution is checked in the separated adding-test.
 
-	// Note: no need to check the workflow, because `golang.org/x/sync/errgroup` package is used.
-	// So can be sure all is good.
+	t.Run("first error cancels others", func(t *testing.T) {
+		errFirst := errors.New("first")
+
+		var cancelledErr error
+
+		err := goroutiner.New().Batch(ctx).
+			Add(func(ctx context.Context) error {
+				return errFirst
+			}).
+			Add(func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					cancelledErr = ctx.Err()
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return nil
+				}
+			}).
+			CancelOnError()
+
+		assert.Equal(t, errFirst, err)
+		assert.Equal(t, context.Canceled, cancelledErr)
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		err := goroutiner.New().Batch(ctx).AddRange(5, func(i int) (G, []Mw) { return g, nil }).CancelOnError()
+		assert.NoError(t, err)
+	})
+
+	t.Run("optional goroutines", func(t *testing.T) {
+		errOptional := errors.New("optional")
+		errCritical := errors.New("critical")
+
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddOptional(nil) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddOptional(g, nil) })
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).CancelOnErrorWithOptional() })
+
+		var cancelledErr error
+		completed := false
+
+		optionalErrs, err := goroutiner.New().Batch(ctx).
+			AddOptional(func(ctx context.Context) error { return errOptional }).
+			Add(func(ctx context.Context) error {
+				// optional goroutine failure does not cancel critical ones
+				time.Sleep(10 * time.Millisecond)
+				completed = true
+				return errCritical
+			}).
+			AddOptional(func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					cancelledErr = ctx.Err()
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return nil
+				}
+			}, mw).
+			AddOptional(g).
+			CancelOnErrorWithOptional()
+
+		assert.True(t, completed)
+		assert.Equal(t, errCritical, err)
+		// critical goroutine failure cancels optional ones too
+		assert.Equal(t, context.Canceled, cancelledErr)
+		assert.Equal(t, []error{errOptional, nil, context.Canceled, nil}, optionalErrs)
+	})
 }
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Batch_ErrorBudget(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+	type Cfg = goroutiner.ErrorBudgetConfig
+
+	ctx := context.TODO()
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			_, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrors: 1})
+			_, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrorRate: 0.5, MinCompleted: 1})
+			_, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrors: 1, MaxErrorRate: 0.5, MinCompleted: 10})
+		})
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).ErrorBudget(Cfg{MaxErrors: 1}) })
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{}) })
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrors: -1}) })
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrorRate: -0.1}) })
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrorRate: 1}) })
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrorRate: 0.5}) })
+		assert.Panics(t, func() {
+			_, _ = goroutiner.New().Batch(ctx).Add(g).ErrorBudget(Cfg{MaxErrorRate: 0.5, MinCompleted: -1})
+		})
+	})
+
+	errTest := errors.New("test")
+
+	// provide returns goroutines, which fail if `fails[i]`, and are executed strictly in order (via Limit(1)).
+	provide := func(fails ...bool) func(i int) (G, []Mw) {
+		return func(i int) (G, []Mw) {
+			return func(ctx context.Context) error {
+				if fails[i] {
+					return errTest
+				}
+				return nil
+			}, nil
+		}
+	}
+
+	for _, tCase := range []struct {
+		name              string
+		cfg               Cfg
+		fails             []bool
+		expectedErrs      []error
+		expectedExhausted bool
+	}{
+		{
+			name:         "max errors not exceeded",
+			cfg:          Cfg{MaxErrors: 2},
+			fails:        []bool{true, false, true, false},
+			expectedErrs: []error{errTest, nil, errTest, nil},
+		},
+		{
+			name:              "max errors exceeded",
+			cfg:               Cfg{MaxErrors: 1},
+			fails:             []bool{true, false, true, false},
+			expectedErrs:      []error{errTest, nil, errTest, context.Canceled},
+			expectedExhausted: true,
+		},
+		{
+			name:         "rate not exceeded",
+			cfg:          Cfg{MaxErrorRate: 0.5, MinCompleted: 2},
+			fails:        []bool{false, true, false, true},
+			expectedErrs: []error{nil, errTest, nil, errTest},
+		},
+		{
+			name:              "rate exceeded",
+			cfg:               Cfg{MaxErrorRate: 0.5, MinCompleted: 2},
+			fails:             []bool{false, true, true, false},
+			expectedErrs:      []error{nil, errTest, errTest, context.Canceled},
+			expectedExhausted: true,
+		},
+		{
+			name:              "rate exceeded after min completed",
+			cfg:               Cfg{MaxErrorRate: 0.5, MinCompleted: 3},
+			fails:             []bool{true, true, false, false, false},
+			expectedErrs:      []error{errTest, errTest, nil, context.Canceled, context.Canceled},
+			expectedExhausted: true,
+		},
+		{
+			name:         "rate not exceeded after min completed",
+			cfg:          Cfg{MaxErrorRate: 0.7, MinCompleted: 3},
+			fails:        []bool{true, true, false, false, false},
+			expectedErrs: []error{errTest, errTest, nil, nil, nil},
+		},
+	} {
+		t.Run(tCase.name, func(t *testing.T) {
+			errs, exhausted := goroutiner.New().Batch(ctx).Limit(1).
+				AddRange(len(tCase.fails), provide(tCase.fails...)).
+				ErrorBudget(tCase.cfg)
+
+			assert.Equal(t, tCase.expectedErrs, errs)
+			assert.Equal(t, tCase.expectedExhausted, exhausted)
+		})
+	}
+
+	t.Run("optional goroutines are not counted", func(t *testing.T) {
+		errs, exhausted := goroutiner.New().Batch(ctx).Limit(1).
+			AddOptional(func(ctx context.Context) error { return errTest }).
+			AddOptional(func(ctx context.Context) error { return errTest }).
+			Add(func(ctx context.Context) error { return errTest }).
+			Add(g).
+			ErrorBudget(Cfg{MaxErrors: 1})
+
+		assert.Equal(t, []error{errTest, errTest, errTest, nil}, errs)
+		assert.False(t, exhausted)
+	})
+
+	t.Run("running goroutines are cancelled", func(t *testing.T) {
+		errs, exhausted := goroutiner.New().Batch(ctx).
+			Add(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}).
+			Add(func(ctx context.Context) error { return errTest }).
+			ErrorBudget(Cfg{MaxErrorRate: 0.5, MinCompleted: 1})
+
+		assert.Equal(t, []error{context.Canceled, errTest}, errs)
+		assert.True(t, exhausted)
+	})
+}
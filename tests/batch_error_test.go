@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_BatchError(t *testing.T) {
+	ctx := context.TODO()
+
+	errA := errors.New("a failed")
+	errB := &goroutiner.TimeoutError{Err: errors.New("b failed")}
+
+	t.Run("no errors", func(t *testing.T) {
+		assert.NoError(t, goroutiner.NewBatchError(nil))
+		assert.NoError(t, goroutiner.NewBatchError(make([]error, 3)))
+
+		err := goroutiner.New().Batch(ctx).
+			Add(func(ctx context.Context) error { return nil }).
+			Add(func(ctx context.Context) error { return nil }).
+			WaitErr()
+
+		// must be untyped nil
+		assert.True(t, err == nil)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := goroutiner.New().Batch(ctx).
+			Add(func(ctx context.Context) error { return nil }).
+			Add(func(ctx context.Context) error { return errA }).
+			Add(func(ctx context.Context) error { return nil }).
+			Add(func(ctx context.Context) error { return errB }).
+			WaitErr()
+
+		var batchErr *goroutiner.BatchError
+		require.True(t, errors.As(err, &batchErr))
+
+		assert.Equal(t, []error{nil, errA, nil, errB}, batchErr.Errors)
+		assert.Equal(t, []int{1, 3}, batchErr.Failed())
+		assert.Equal(t, 2, batchErr.FailedCount())
+
+		assert.ErrorIs(t, err, errA)
+		assert.ErrorIs(t, err, goroutiner.ErrTimeout)
+		assert.NotErrorIs(t, err, context.Canceled)
+
+		var timeoutErr *goroutiner.TimeoutError
+		require.True(t, errors.As(err, &timeoutErr))
+		assert.Same(t, errB, timeoutErr)
+
+		assert.Equal(t, errA, errors.Unwrap(err))
+
+		assert.EqualError(
+			t,
+			err,
+			"2 of 4 goroutine(s) failed\n  goroutine #1: a failed\n  goroutine #3: goroutine timeout (0s): b failed",
+		)
+	})
+
+	t.Run("errors are copied", func(t *testing.T) {
+		errs := []error{errA, nil}
+
+		err := goroutiner.NewBatchError(errs)
+		errs[1] = errA
+
+		assert.Equal(t, []int{0}, err.(*goroutiner.BatchError).Failed())
+	})
+}
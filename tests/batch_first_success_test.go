@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Batch_FirstSuccess(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+
+	t.Run("panic no goroutines", func(t *testing.T) {
+		g := func(ctx context.Context) error { return nil }
+
+		assert.NotPanics(t, func() {
+			_, _ = goroutiner.New().Batch(ctx).Add(g).FirstSuccess()
+			_, _ = goroutiner.New().Batch(ctx).AddRange(2, func(i int) (G, []Mw) { return g, nil }).FirstSuccess()
+		})
+		assert.Panics(t, func() { _, _ = goroutiner.New().Batch(ctx).FirstSuccess() })
+	})
+
+	t.Run("first success cancels others", func(t *testing.T) {
+		var cancelled int64
+
+		slow := func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				atomic.AddInt64(&cancelled, 1)
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		}
+
+		winner, errs := goroutiner.New().Batch(ctx).
+			Add(slow).
+			Add(func(ctx context.Context) error { return fmt.Errorf("failed") }).
+			Add(func(ctx context.Context) error {
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			}).
+			Add(slow).
+			FirstSuccess()
+
+		assert.Equal(t, 2, winner)
+		assert.Nil(t, errs)
+		// others are awaited to be completed
+		assert.Equal(t, int64(2), atomic.LoadInt64(&cancelled))
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		winner, errs := goroutiner.New().Batch(ctx).
+			AddRange(3, func(i int) (G, []Mw) {
+				return func(ctx context.Context) error { return fmt.Errorf("error #%d", i) }, nil
+			}).
+			FirstSuccess()
+
+		assert.Equal(t, -1, winner)
+		assert.Equal(t, []error{fmt.Errorf("error #0"), fmt.Errorf("error #1"), fmt.Errorf("error #2")}, errs)
+	})
+
+	t.Run("queued goroutines are not started after success", func(t *testing.T) {
+		var started int64
+
+		winner, errs := goroutiner.New().Batch(ctx).Limit(1).
+			AddRange(3, func(i int) (G, []Mw) {
+				return func(ctx context.Context) error {
+					atomic.AddInt64(&started, 1)
+					return nil
+				}, nil
+			}).
+			FirstSuccess()
+
+		assert.Equal(t, 0, winner)
+		assert.Nil(t, errs)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&started))
+	})
+}
@@ -0,0 +1,249 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Batch_Graph(t *testing.T) {
+	type G = goroutiner.Goroutine
+
+	ctx := context.TODO()
+	mw := func(g G) G { return g }
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.New().Batch(ctx).AddNode("a", nil, g)
+			goroutiner.New().Batch(ctx).AddNode("a", nil, g, mw).AddNode("b", []string{"a"}, g).Add(g)
+			goroutiner.New().Batch(ctx).AddNode("b", []string{"a"}, g).AddNode("a", nil, g).Graph()
+		})
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNode("", nil, g) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNode("a", nil, g).AddNode("a", nil, g) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNode("a", []string{""}, g) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNode("a", nil, nil) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNode("a", nil, g, nil) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).Graph() })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNode("a", []string{"x"}, g).Graph() })
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		for _, tCase := range []struct {
+			name     string
+			b        *goroutiner.Batch
+			expected string
+		}{
+			{"valid", goroutiner.New().Batch(ctx).AddNode("a", nil, g).AddNode("b", []string{"a"}, g).Add(g), ""},
+			{"unknown", goroutiner.New().Batch(ctx).AddNode("a", []string{"x"}, g), `"a" depends on unknown "x"`},
+			{"itself", goroutiner.New().Batch(ctx).AddNode("a", []string{"a"}, g), `"a" depends on itself`},
+			{
+				"cycle",
+				goroutiner.New().Batch(ctx).
+					AddNode("a", nil, g).
+					AddNode("b", []string{"a", "d"}, g).
+					AddNode("c", []string{"b"}, g).
+					AddNode("d", []string{"c"}, g),
+				`dependency cycle among "b", "c", "d"`,
+			},
+		} {
+			err := tCase.b.ValidateGraph()
+			if tCase.expected == "" {
+				assert.NoError(t, err, tCase.name)
+			} else {
+				assert.EqualError(t, err, tCase.expected, tCase.name)
+				assert.Panics(t, func() { tCase.b.Graph() }, tCase.name)
+			}
+		}
+	})
+
+	t.Run("dependencies order", func(t *testing.T) {
+		mu := new(sync.Mutex)
+		completed := make(map[string]bool)
+
+		mG := func(name string, deps ...string) G {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				for _, dep := range deps {
+					assert.True(t, completed[dep], "%s started before %s completed", name, dep)
+				}
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				completed[name] = true
+				mu.Unlock()
+				return nil
+			}
+		}
+
+		for _, limit := range []int{0, 1, 2} {
+			completed = make(map[string]bool)
+
+			errs := goroutiner.New().Batch(ctx).Limit(limit).
+				AddNode("http", []string{"cache", "migrations"}, mG("http", "cache", "migrations")).
+				AddNode("cache", []string{"migrations"}, mG("cache", "migrations")).
+				AddNode("migrations", nil, mG("migrations")).
+				Add(mG("independent")).
+				Graph()
+
+			assert.Equal(t, make([]error, 4), errs, "limit %d", limit)
+			assert.Len(t, completed, 4, "limit %d", limit)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		mu := new(sync.Mutex)
+		started := make([]string, 0)
+
+		mG := func(name string) G {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				started = append(started, name)
+				mu.Unlock()
+				return nil
+			}
+		}
+
+		// ready goroutines are started in the adding order
+		errs := goroutiner.New().Batch(ctx).Limit(1).
+			AddNode("a", nil, mG("a")).
+			AddNode("b", []string{"a"}, mG("b")).
+			AddNode("c", nil, mG("c")).
+			Add(mG("d")).
+			Graph()
+
+		assert.Equal(t, make([]error, 4), errs)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, started)
+
+		// goroutines are not started in advance
+		maxGoroutines := 0
+		baseGoroutines := runtime.NumGoroutine()
+
+		errs = goroutiner.New().Batch(ctx).Limit(10).
+			AddRange(1000, func(i int) (G, []goroutiner.Middleware) {
+				return func(ctx context.Context) error {
+					mu.Lock()
+					if n := runtime.NumGoroutine(); n > maxGoroutines {
+						maxGoroutines = n
+					}
+					mu.Unlock()
+					return nil
+				}, nil
+			}).
+			Graph()
+
+		assert.Equal(t, make([]error, 1000), errs)
+		// completed goroutines may still be exiting, while next ones are started
+		assert.Less(t, maxGoroutines, baseGoroutines+50)
+	})
+
+	t.Run("dependents of failed nodes are skipped", func(t *testing.T) {
+		errMigrations := errors.New("migrations failed")
+		executed := make(chan string, 10)
+
+		mG := func(name string, err error) G {
+			return func(ctx context.Context) error {
+				executed <- name
+				return err
+			}
+		}
+
+		errs := goroutiner.New().Batch(ctx).
+			AddNode("migrations", nil, mG("migrations", errMigrations)).
+			AddNode("cache", []string{"migrations"}, mG("cache", nil)).
+			AddNode("http", []string{"cache"}, mG("http", nil)).
+			AddNode("metrics", nil, mG("metrics", nil)).
+			Graph()
+		close(executed)
+
+		names := make([]string, 0)
+		for name := range executed {
+			names = append(names, name)
+		}
+		assert.ElementsMatch(t, []string{"migrations", "metrics"}, names)
+
+		assert.Equal(t, errMigrations, errs[0])
+		assert.Nil(t, errs[3])
+
+		for _, tCase := range []struct {
+			err  error
+			node string
+			dep  string
+		}{
+			{errs[1], "cache", "migrations"},
+			{errs[2], "http", "cache"},
+		} {
+			assert.ErrorIs(t, tCase.err, goroutiner.ErrSkipped)
+			assert.ErrorIs(t, tCase.err, errMigrations)
+
+			var skippedErr *goroutiner.SkippedError
+			require.True(t, errors.As(tCase.err, &skippedErr))
+			assert.Equal(t, tCase.node, skippedErr.Node)
+			assert.Equal(t, tCase.dep, skippedErr.Dependency)
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		executed := false
+		mG := func(ctx context.Context) error {
+			executed = true
+			return nil
+		}
+
+		errs := goroutiner.New().Batch(ctx).
+			AddNode("a", nil, func(ctx context.Context) error {
+				cancel()
+				return nil
+			}).
+			AddNode("b", []string{"a"}, mG).
+			Graph()
+
+		assert.Equal(t, []error{nil, context.Canceled}, errs)
+		assert.False(t, executed)
+
+		// dependents of goroutines failed after the context is done are not skipped, but not started too
+		ctx, cancel = context.WithCancel(context.TODO())
+		defer cancel()
+
+		errs = goroutiner.New().Batch(ctx).
+			AddNode("a", nil, func(ctx context.Context) error {
+				cancel()
+				return ctx.Err()
+			}).
+			AddNode("b", []string{"a"}, mG).
+			AddNode("c", []string{"b"}, mG).
+			Graph()
+
+		assert.Equal(t, []error{context.Canceled, context.Canceled, context.Canceled}, errs)
+		assert.False(t, executed)
+	})
+
+	t.Run("named errors", func(t *testing.T) {
+		errFail := errors.New("fail")
+
+		err := goroutiner.New().Batch(ctx).
+			AddNode("a", nil, func(ctx context.Context) error { return errFail }).
+			AddNode("b", []string{"a"}, g).
+			Add(g).
+			GraphErr()
+
+		var batchErr *goroutiner.BatchError
+		require.True(t, errors.As(err, &batchErr))
+		assert.Equal(t, []string{"a", "b", ""}, batchErr.Names)
+		assert.Equal(t, []int{0, 1}, batchErr.Failed())
+		assert.ErrorIs(t, batchErr.Errors[1], goroutiner.ErrSkipped)
+
+		assert.NoError(t, goroutiner.New().Batch(ctx).AddNode("a", nil, g).GraphErr())
+	})
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Batch_Limit(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.New().Batch(ctx).Limit(0)
+			goroutiner.New().Batch(ctx).Limit(1)
+			goroutiner.New().Batch(ctx).Limit(100)
+		})
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).Limit(-1) })
+	})
+
+	t.Run("running goroutines never exceed the limit", func(t *testing.T) {
+		const numOfGoroutines = 20
+
+		var running, maxRunning, executed int64
+
+		g := func(ctx context.Context) error {
+			cur := atomic.AddInt64(&running, 1)
+			for {
+				prev := atomic.LoadInt64(&maxRunning)
+				if cur <= prev || atomic.CompareAndSwapInt64(&maxRunning, prev, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt64(&running, -1)
+			atomic.AddInt64(&executed, 1)
+			return nil
+		}
+
+		strategies := map[string]func(b *goroutiner.Batch){
+			"Wait":          func(b *goroutiner.Batch) { _ = b.Wait() },
+			"CancelOnError": func(b *goroutiner.Batch) { _ = b.CancelOnError() },
+			"Async": func(b *goroutiner.Batch) {
+				for range b.Async() {
+				}
+			},
+			"AsyncBs": func(b *goroutiner.Batch) {
+				for range b.AsyncBs(0) {
+				}
+			},
+		}
+
+		for name, fnExecute := range strategies {
+			for _, limit := range []int{1, 2, 5, numOfGoroutines} {
+				maxRunning, executed = 0, 0
+
+				b := goroutiner.New().Batch(ctx).Limit(limit).AddRange(numOfGoroutines, func(i int) (G, []Mw) {
+					return g, nil
+				})
+				fnExecute(b)
+
+				assert.Equal(t, int64(numOfGoroutines), executed, "%s - limit %d", name, limit)
+				assert.LessOrEqual(t, maxRunning, int64(limit), "%s - limit %d", name, limit)
+			}
+		}
+	})
+
+	t.Run("queued goroutines respect context cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		started := make([]bool, 3)
+		mu := new(sync.Mutex)
+
+		errs := goroutiner.New().Batch(ctx).Limit(1).AddRange(3, func(i int) (G, []Mw) {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				started[i] = true
+				mu.Unlock()
+
+				// the first goroutine cancels the context, so others must not be started.
+				cancel()
+				<-ctx.Done()
+				return nil
+			}, nil
+		}).Wait()
+
+		assert.Equal(t, []bool{true, false, false}, started)
+		assert.Equal(t, []error{nil, context.Canceled, context.Canceled}, errs)
+	})
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Batch_PropagatePanics(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+
+	recoverPanic := func(fn func()) (pv any) {
+		defer func() {
+			pv = recover()
+		}()
+		fn()
+		return nil
+	}
+
+	t.Run("Wait - other goroutines complete", func(t *testing.T) {
+		var completed int64
+
+		pv := recoverPanic(func() {
+			goroutiner.New().Batch(ctx).PropagatePanics().
+				Add(func(ctx context.Context) error {
+					atomic.AddInt64(&completed, 1)
+					return nil
+				}).
+				Add(func(ctx context.Context) error {
+					panic("Big Panic in Little Goroutine")
+				}).
+				Add(func(ctx context.Context) error {
+					time.Sleep(10 * time.Millisecond)
+					atomic.AddInt64(&completed, 1)
+					return nil
+				}).
+				Wait()
+		})
+
+		gp, ok := pv.(*goroutiner.GoroutinePanic)
+		require.True(t, ok, "%#v", pv)
+		assert.Equal(t, 1, gp.Index)
+		assert.Equal(t, "Big Panic in Little Goroutine", gp.Value)
+		assert.NotEmpty(t, gp.Stack)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&completed))
+	})
+
+	t.Run("CancelOnError - other goroutines are cancelled", func(t *testing.T) {
+		var cancelledErr error
+
+		pv := recoverPanic(func() {
+			_ = goroutiner.New().Batch(ctx).PropagatePanics().
+				Add(func(ctx context.Context) error {
+					select {
+					case <-ctx.Done():
+						cancelledErr = ctx.Err()
+						return ctx.Err()
+					case <-time.After(time.Second):
+						return nil
+					}
+				}).
+				Add(func(ctx context.Context) error {
+					panic(errors.New("panic error"))
+				}).
+				CancelOnError()
+		})
+
+		gp, ok := pv.(*goroutiner.GoroutinePanic)
+		require.True(t, ok, "%#v", pv)
+		assert.Equal(t, 1, gp.Index)
+		assert.EqualError(t, errors.Unwrap(gp), "panic error")
+		assert.Equal(t, context.Canceled, cancelledErr)
+	})
+
+	t.Run("TypedBatch", func(t *testing.T) {
+		pv := recoverPanic(func() {
+			_, _ = goroutiner.NewTypedBatch[int](goroutiner.New(), ctx).PropagatePanics().
+				Add(func(ctx context.Context) (int, error) { panic("typed") }).
+				Wait()
+		})
+
+		gp, ok := pv.(*goroutiner.GoroutinePanic)
+		require.True(t, ok, "%#v", pv)
+		assert.Equal(t, "typed", gp.Value)
+	})
+
+	t.Run("panic middleware still work", func(t *testing.T) {
+		mwPanicToError := goroutiner.MwPanicToError(func(panicValue any, debugStack []byte, ctx context.Context) error {
+			return errors.New("converted")
+		})
+
+		var errs []error
+		assert.NotPanics(t, func() {
+			errs = goroutiner.New(mwPanicToError).Batch(ctx).PropagatePanics().
+				Add(func(ctx context.Context) error { panic("converted") }).
+				Wait()
+		})
+		assert.EqualError(t, errs[0], "converted")
+	})
+
+	t.Run("no panics", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			b := goroutiner.New().Batch(ctx).PropagatePanics().
+				AddRange(3, func(i int) (G, []Mw) {
+					return func(ctx context.Context) error { return nil }, nil
+				})
+			_ = b.Wait()
+			_ = b.CancelOnError()
+		})
+	})
+}
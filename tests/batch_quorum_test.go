@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Batch_Quorum(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+
+	t.Run("panic arguments", func(t *testing.T) {
+		g := func(ctx context.Context) error { return nil }
+		mB := func(n int) *goroutiner.Batch {
+			return goroutiner.New().Batch(ctx).AddRange(n, func(i int) (G, []Mw) { return g, nil })
+		}
+
+		assert.NotPanics(t, func() {
+			_, _, _ = mB(1).Quorum(1)
+			_, _, _ = mB(3).Quorum(2)
+			_, _, _ = mB(3).Quorum(3)
+		})
+		assert.Panics(t, func() { _, _, _ = mB(3).Quorum(0) })
+		assert.Panics(t, func() { _, _, _ = mB(3).Quorum(-1) })
+		assert.Panics(t, func() { _, _, _ = mB(3).Quorum(4) })
+		assert.Panics(t, func() { _, _, _ = goroutiner.New().Batch(ctx).Quorum(1) })
+	})
+
+	// mG returns a goroutine, which succeeds or fails immediately, or waits for the context cancellation.
+	mG := func(result string, cancelled *int64) G {
+		return func(ctx context.Context) error {
+			switch result {
+			case "ok":
+				return nil
+			case "err":
+				return fmt.Errorf("err")
+			default:
+				select {
+				case <-ctx.Done():
+					atomic.AddInt64(cancelled, 1)
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return nil
+				}
+			}
+		}
+	}
+
+	t.Run("quorum reached", func(t *testing.T) {
+		var cancelled int64
+
+		succeeded, errs, ok := goroutiner.New().Batch(ctx).
+			Add(mG("ok", &cancelled)).
+			Add(mG("err", &cancelled)).
+			Add(mG("ok", &cancelled)).
+			Add(mG("wait", &cancelled)).
+			Add(mG("wait", &cancelled)).
+			Quorum(2)
+
+		assert.True(t, ok)
+		assert.Equal(t, []int{0, 2}, succeeded)
+		assert.Equal(t, []error{nil, fmt.Errorf("err"), nil, context.Canceled, context.Canceled}, errs)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&cancelled))
+	})
+
+	t.Run("quorum impossible", func(t *testing.T) {
+		var cancelled int64
+
+		succeeded, errs, ok := goroutiner.New().Batch(ctx).
+			Add(mG("err", &cancelled)).
+			Add(mG("ok", &cancelled)).
+			Add(mG("err", &cancelled)).
+			Add(mG("wait", &cancelled)).
+			Quorum(3)
+
+		assert.False(t, ok)
+		assert.Equal(t, []int{1}, succeeded)
+		assert.Equal(t, []error{fmt.Errorf("err"), nil, fmt.Errorf("err"), context.Canceled}, errs)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&cancelled))
+	})
+
+	t.Run("all required", func(t *testing.T) {
+		succeeded, errs, ok := goroutiner.New().Batch(ctx).
+			AddRange(3, func(i int) (G, []Mw) { return mG("ok", nil), nil }).
+			Quorum(3)
+
+		assert.True(t, ok)
+		assert.Equal(t, []int{0, 1, 2}, succeeded)
+		assert.Equal(t, []error{nil, nil, nil}, errs)
+	})
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Batch_Sequential(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			_ = goroutiner.New().Batch(ctx).Add(g).Sequential(goroutiner.SequentialStopOnError)
+			_ = goroutiner.New().Batch(ctx).AddRange(2, func(i int) (G, []Mw) { return g, nil }).Sequential(goroutiner.SequentialRunAll)
+		})
+		assert.Panics(t, func() { _ = goroutiner.New().Batch(ctx).Sequential(goroutiner.SequentialStopOnError) })
+		assert.Panics(t, func() { _ = goroutiner.New().Batch(ctx).Add(g).Sequential(goroutiner.SequentialMode(-1)) })
+	})
+
+	errTest := errors.New("test")
+
+	for _, tCase := range []struct {
+		name          string
+		mode          goroutiner.SequentialMode
+		expectedOrder []int
+		expectedErrs  []error
+	}{
+		{
+			name:          "stop on error",
+			mode:          goroutiner.SequentialStopOnError,
+			expectedOrder: []int{0, 1},
+			expectedErrs:  []error{nil, errTest, goroutiner.ErrSkipped, goroutiner.ErrSkipped},
+		},
+		{
+			name:          "run all",
+			mode:          goroutiner.SequentialRunAll,
+			expectedOrder: []int{0, 1, 2, 3},
+			expectedErrs:  []error{nil, errTest, nil, errTest},
+		},
+	} {
+		t.Run(tCase.name, func(t *testing.T) {
+			order := make([]int, 0)
+			mw := func(g G) G {
+				return func(ctx context.Context) error {
+					// goroutines are executed on the calling goroutine, so no synchronization is required.
+					order = append(order, len(order))
+					return g(ctx)
+				}
+			}
+
+			errs := goroutiner.New(mw).Batch(ctx).
+				Add(g).
+				Add(func(ctx context.Context) error { return errTest }).
+				Add(g).
+				Add(func(ctx context.Context) error { return errTest }).
+				Sequential(tCase.mode)
+
+			assert.Equal(t, tCase.expectedOrder, order)
+			assert.Equal(t, tCase.expectedErrs, errs)
+		})
+	}
+
+	t.Run("context done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		executed := 0
+
+		errs := goroutiner.New().Batch(ctx).
+			Add(func(ctx context.Context) error {
+				executed++
+				cancel()
+				return nil
+			}).
+			Add(func(ctx context.Context) error {
+				executed++
+				return nil
+			}).
+			Sequential(goroutiner.SequentialRunAll)
+
+		assert.Equal(t, 1, executed)
+		assert.Equal(t, []error{nil, context.Canceled}, errs)
+	})
+
+	t.Run("panics", func(t *testing.T) {
+		executed := 0
+
+		gs := []G{
+			func(ctx context.Context) error { panic("first") },
+			func(ctx context.Context) error {
+				executed++
+				return nil
+			},
+		}
+
+		assert.PanicsWithValue(t, "first", func() {
+			_ = goroutiner.New().Batch(ctx).Add(gs[0]).Add(gs[1]).Sequential(goroutiner.SequentialRunAll)
+		})
+		assert.Equal(t, 0, executed)
+
+		assert.Panics(t, func() {
+			defer func() {
+				pv := recover()
+				assert.IsType(t, &goroutiner.GoroutinePanic{}, pv)
+				panic(pv)
+			}()
+			_ = goroutiner.New().Batch(ctx).PropagatePanics().Add(gs[0]).Add(gs[1]).Sequential(goroutiner.SequentialRunAll)
+		})
+		assert.Equal(t, 1, executed)
+	})
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+)
+
+func Test_Batch_Start(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+
+	// mG returns an error, if `i` is odd.
+	mG := func(i int) G {
+		return func(ctx context.Context) error {
+			if i%2 == 1 {
+				return fmt.Errorf("error #%d", i)
+			}
+			return nil
+		}
+	}
+
+	waitCtx := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	t.Run("panic no goroutines", func(t *testing.T) {
+		assert.NotPanics(t, func() { goroutiner.New().Batch(ctx).Add(mG(0)).Start().Wait() })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).Start() })
+	})
+
+	t.Run("Wait", func(t *testing.T) {
+		h := goroutiner.New().Batch(ctx).AddRange(4, func(i int) (G, []Mw) { return mG(i), nil }).Start()
+
+		expected := []error{nil, fmt.Errorf("error #1"), nil, fmt.Errorf("error #3")}
+		assert.Equal(t, expected, h.Wait())
+		// can be called several times
+		assert.Equal(t, expected, h.Wait())
+
+		select {
+		case <-h.Done():
+		default:
+			assert.Fail(t, "must be done after Wait")
+		}
+	})
+
+	t.Run("Results", func(t *testing.T) {
+		h := goroutiner.New().Batch(ctx).AddRange(4, func(i int) (G, []Mw) { return mG(i), nil }).Start()
+
+		results := make([]goroutiner.IndexedError, 0)
+		for res := range h.Results() {
+			results = append(results, res)
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+		assert.Equal(t, []goroutiner.IndexedError{
+			{Index: 0, Err: nil},
+			{Index: 1, Err: fmt.Errorf("error #1")},
+			{Index: 2, Err: nil},
+			{Index: 3, Err: fmt.Errorf("error #3")},
+		}, results)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		h := goroutiner.New().Batch(ctx).Add(waitCtx).Add(waitCtx).Start()
+
+		select {
+		case <-h.Done():
+			assert.Fail(t, "must not be done before Cancel")
+		case <-time.After(5 * time.Millisecond):
+		}
+
+		h.Cancel()
+
+		assert.Equal(t, []error{context.Canceled, context.Canceled}, h.Wait())
+	})
+
+	t.Run("Discard does not leak", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		for i := 0; i < 10; i++ {
+			goroutiner.New().Batch(ctx).Add(waitCtx).Add(mG(1)).Start().Discard()
+			goroutiner.New().Batch(ctx).Add(mG(1)).Add(mG(2)).Start() // nobody reads results
+		}
+
+		// note: assert.Eventually can't be used, as it runs the condition in a separate goroutine.
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
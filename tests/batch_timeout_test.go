@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"context"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_Batch_Timeout(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+
+	g := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.New().Batch(ctx).Timeout(0)
+			goroutiner.New().Batch(ctx).Timeout(time.Second)
+			goroutiner.New().Batch(ctx).Deadline(time.Time{})
+			goroutiner.New().Batch(ctx).Deadline(time.Now())
+		})
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).Timeout(-1) })
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		b := goroutiner.New().Batch(ctx).Timeout(5 * time.Millisecond).Add(g).Add(g)
+
+		// the timeout is applied to each execution separately
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, []error{context.DeadlineExceeded, context.DeadlineExceeded}, b.Wait())
+			assert.Equal(t, context.DeadlineExceeded, b.CancelOnError())
+
+			for err := range b.Async() {
+				assert.Equal(t, context.DeadlineExceeded, err)
+			}
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		start := time.Now()
+
+		errs := goroutiner.New().Batch(ctx).Deadline(time.Now().Add(5 * time.Millisecond)).Add(g).Wait()
+
+		assert.Equal(t, []error{context.DeadlineExceeded}, errs)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("earliest is applied", func(t *testing.T) {
+		start := time.Now()
+		_ = goroutiner.New().Batch(ctx).Timeout(time.Hour).Deadline(time.Now().Add(5 * time.Millisecond)).Add(g).Wait()
+		assert.Less(t, time.Since(start), time.Second)
+
+		start = time.Now()
+		_ = goroutiner.New().Batch(ctx).Timeout(5 * time.Millisecond).Deadline(time.Now().Add(time.Hour)).Add(g).Wait()
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("queued goroutines are not started after timeout", func(t *testing.T) {
+		errs := goroutiner.New().Batch(ctx).Timeout(5*time.Millisecond).Limit(1).
+			AddRange(3, func(i int) (G, []Mw) { return g, nil }).
+			Wait()
+
+		assert.Equal(t, []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded}, errs)
+	})
+}
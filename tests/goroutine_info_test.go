@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_GoroutineInfo(t *testing.T) {
+	type G = goroutiner.Goroutine
+
+	ctx := context.TODO()
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("no info", func(t *testing.T) {
+		_, ok := goroutiner.InfoFromContext(ctx)
+		assert.False(t, ok)
+	})
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() { goroutiner.New().Batch(ctx).AddNamed("a", g).AddNamed("b", g).Add(g) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNamed("", g) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNamed("a", g).AddNamed("a", g) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNamed("a", nil) })
+		assert.Panics(t, func() { goroutiner.New().Batch(ctx).AddNamed("a", g, nil) })
+	})
+
+	// infos collects infos seen by the global middleware and by goroutines.
+	type infos struct {
+		mu     sync.Mutex
+		fromMw map[int]goroutiner.GoroutineInfo
+		fromG  map[int]goroutiner.GoroutineInfo
+	}
+
+	collect := func(ii *infos, m map[int]goroutiner.GoroutineInfo, ctx context.Context) {
+		info, ok := goroutiner.InfoFromContext(ctx)
+		require.True(t, ok)
+
+		ii.mu.Lock()
+		m[info.Index] = info
+		ii.mu.Unlock()
+	}
+
+	newInfos := func() (*infos, goroutiner.Middleware, G) {
+		ii := &infos{
+			fromMw: make(map[int]goroutiner.GoroutineInfo),
+			fromG:  make(map[int]goroutiner.GoroutineInfo),
+		}
+
+		mw := func(g G) G {
+			return func(ctx context.Context) error {
+				collect(ii, ii.fromMw, ctx)
+				return g(ctx)
+			}
+		}
+
+		return ii, mw, func(ctx context.Context) error {
+			collect(ii, ii.fromG, ctx)
+			return nil
+		}
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		ii, mw, g := newInfos()
+		grt := goroutiner.New(mw)
+
+		before := time.Now()
+
+		b := grt.Batch(ctx).AddNamed("first", g).Add(g).AddNamed("third", g)
+		b.Wait()
+
+		require.Len(t, ii.fromG, 3)
+		assert.Equal(t, ii.fromMw, ii.fromG)
+
+		batchID := ii.fromG[0].BatchID
+		assert.NotZero(t, batchID)
+
+		for i, name := range []string{"first", "", "third"} {
+			info := ii.fromG[i]
+			assert.Equal(t, i, info.Index)
+			assert.Equal(t, name, info.Name)
+			assert.Equal(t, batchID, info.BatchID)
+			assert.Equal(t, "Wait", info.Strategy)
+			assert.False(t, info.StartedAt.Before(before))
+		}
+
+		// same batch -- same ID
+		_ = b.CancelOnError()
+		assert.Equal(t, batchID, ii.fromG[0].BatchID)
+		assert.Equal(t, "CancelOnError", ii.fromG[0].Strategy)
+
+		// other batch -- other ID
+		grt.Batch(ctx).Add(g).Wait()
+		assert.NotEqual(t, batchID, ii.fromG[0].BatchID)
+	})
+
+	t.Run("strategies", func(t *testing.T) {
+		for _, tCase := range []struct {
+			strategy string
+			fnRun    func(grt *goroutiner.Goroutiner, g G)
+		}{
+			{"Wait", func(grt *goroutiner.Goroutiner, g G) { grt.Batch(ctx).Add(g).Wait() }},
+			{"Wait", func(grt *goroutiner.Goroutiner, g G) { _ = grt.Batch(ctx).Add(g).WaitErr() }},
+			{"CancelOnError", func(grt *goroutiner.Goroutiner, g G) { _ = grt.Batch(ctx).Add(g).CancelOnError() }},
+			{"FirstSuccess", func(grt *goroutiner.Goroutiner, g G) { grt.Batch(ctx).Add(g).FirstSuccess() }},
+			{"Quorum", func(grt *goroutiner.Goroutiner, g G) { grt.Batch(ctx).Add(g).Quorum(1) }},
+			{"Sequential", func(grt *goroutiner.Goroutiner, g G) {
+				grt.Batch(ctx).Add(g).Sequential(goroutiner.SequentialRunAll)
+			}},
+			{"Graph", func(grt *goroutiner.Goroutiner, g G) { grt.Batch(ctx).Add(g).Graph() }},
+			{"Async", func(grt *goroutiner.Goroutiner, g G) { <-grt.Batch(ctx).Add(g).Async() }},
+			{"AsyncBs", func(grt *goroutiner.Goroutiner, g G) { <-grt.Batch(ctx).Add(g).AsyncBs(0) }},
+			{"Start", func(grt *goroutiner.Goroutiner, g G) { grt.Batch(ctx).Add(g).Start().Wait() }},
+			{"Wait", func(grt *goroutiner.Goroutiner, g G) { goroutiner.NewKeyedBatch[int](grt, ctx).Add(1, g).Wait() }},
+			{"Saga", func(grt *goroutiner.Goroutiner, g G) { _ = grt.Saga(ctx).Step(g, nil).Run() }},
+		} {
+			ii, mw, g := newInfos()
+
+			tCase.fnRun(goroutiner.New(mw), g)
+
+			require.Len(t, ii.fromG, 1, tCase.strategy)
+			assert.Equal(t, tCase.strategy, ii.fromG[0].Strategy, tCase.strategy)
+		}
+	})
+
+	t.Run("typed batch", func(t *testing.T) {
+		ii, mw, g := newInfos()
+
+		values, _ := goroutiner.NewTypedBatch[string](goroutiner.New(mw), ctx).
+			Add(func(ctx context.Context) (string, error) {
+				info, _ := goroutiner.InfoFromContext(ctx)
+				return fmt.Sprintf("%s #%d", info.Strategy, info.Index), g(ctx)
+			}).
+			Wait()
+
+		assert.Equal(t, []string{"Wait #0"}, values)
+		assert.Equal(t, ii.fromMw, ii.fromG)
+	})
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_KeyedBatch(t *testing.T) {
+	type G = goroutiner.Goroutine
+
+	ctx := context.TODO()
+	mw := func(g G) G { return g }
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		grt := goroutiner.New()
+
+		assert.NotPanics(t, func() {
+			goroutiner.NewKeyedBatch[string](grt, ctx)
+			goroutiner.NewKeyedBatch[int](grt, ctx, mw).Add(1, g).Add(2, g, mw)
+		})
+		assert.Panics(t, func() { goroutiner.NewKeyedBatch[int](nil, ctx) })
+		assert.Panics(t, func() { goroutiner.NewKeyedBatch[int](grt, nil) })
+		assert.Panics(t, func() { goroutiner.NewKeyedBatch[int](grt, ctx, nil) })
+		assert.Panics(t, func() { goroutiner.NewKeyedBatch[int](grt, ctx).Add(1, nil) })
+		assert.Panics(t, func() { goroutiner.NewKeyedBatch[int](grt, ctx).Add(1, g, nil) })
+		assert.Panics(t, func() { _ = goroutiner.NewKeyedBatch[int](grt, ctx).Wait() })
+		assert.Panics(t, func() { _ = goroutiner.NewKeyedBatch[int](grt, ctx).CancelOnError() })
+	})
+
+	t.Run("same keys in order, different keys concurrently", func(t *testing.T) {
+		mu := new(sync.Mutex)
+		order := make(map[string][]int)
+		running := make(map[string]int)
+		var concurrentKeys, maxConcurrentKeys int64
+
+		mG := func(key string, i int) G {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				running[key]++
+				assert.Equal(t, 1, running[key], "key %s runs concurrently", key)
+				order[key] = append(order[key], i)
+				mu.Unlock()
+
+				cur := atomic.AddInt64(&concurrentKeys, 1)
+				for {
+					prev := atomic.LoadInt64(&maxConcurrentKeys)
+					if cur <= prev || atomic.CompareAndSwapInt64(&maxConcurrentKeys, prev, cur) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt64(&concurrentKeys, -1)
+
+				mu.Lock()
+				running[key]--
+				mu.Unlock()
+				return nil
+			}
+		}
+
+		kb := goroutiner.NewKeyedBatch[string](goroutiner.New(), ctx)
+		keys := []string{"a", "b", "a", "c", "b", "a", "c"}
+		for i, key := range keys {
+			kb.Add(key, mG(key, i))
+		}
+
+		errs := kb.Wait()
+
+		assert.Equal(t, make([]error, len(keys)), errs)
+		assert.Equal(t, map[string][]int{"a": {0, 2, 5}, "b": {1, 4}, "c": {3, 6}}, order)
+		assert.Greater(t, maxConcurrentKeys, int64(1))
+
+		// limit
+		order = make(map[string][]int)
+		maxConcurrentKeys = 0
+		_ = kb.Limit(1).Wait()
+		assert.Equal(t, int64(1), maxConcurrentKeys)
+		assert.Equal(t, map[string][]int{"a": {0, 2, 5}, "b": {1, 4}, "c": {3, 6}}, order)
+	})
+
+	t.Run("Wait - errors index-aligned", func(t *testing.T) {
+		errA := errors.New("a")
+		errs := goroutiner.NewKeyedBatch[int](goroutiner.New(), ctx).
+			Add(1, func(ctx context.Context) error { return errA }).
+			Add(2, g).
+			Add(1, g).
+			Wait()
+
+		assert.Equal(t, []error{errA, nil, nil}, errs)
+	})
+
+	t.Run("CancelOnError - rest of sequences are not started", func(t *testing.T) {
+		errA := errors.New("a")
+		var started int64
+
+		err := goroutiner.NewKeyedBatch[int](goroutiner.New(), ctx).
+			Add(1, func(ctx context.Context) error { return errA }).
+			Add(2, func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}).
+			Add(2, func(ctx context.Context) error {
+				atomic.AddInt64(&started, 1)
+				return nil
+			}).
+			Add(1, func(ctx context.Context) error {
+				atomic.AddInt64(&started, 1)
+				return nil
+			}).
+			CancelOnError()
+
+		assert.Equal(t, errA, err)
+		assert.Equal(t, int64(0), started)
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		var actual string
+
+		mMw := func(name string) goroutiner.Middleware {
+			return func(g G) G {
+				return func(ctx context.Context) error {
+					actual += name + "-"
+					return g(ctx)
+				}
+			}
+		}
+
+		_ = goroutiner.NewKeyedBatch[int](goroutiner.New(mMw("g")), ctx, mMw("s")).Add(1, g, mMw("i")).Wait()
+
+		assert.Equal(t, "g-s-i-", actual)
+	})
+}
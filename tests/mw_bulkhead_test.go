@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Mw_Bulkhead(t *testing.T) {
+	type Cfg = goroutiner.BulkheadConfig
+
+	ctx := context.TODO()
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			bh := goroutiner.NewBulkhead(Cfg{Capacity: 2, MaxQueue: 1, AcquireTimeout: time.Second})
+			goroutiner.MwBulkhead(bh, 1)
+			goroutiner.MwBulkhead(bh, 2)
+		})
+		assert.Panics(t, func() { goroutiner.NewBulkhead(Cfg{}) })
+		assert.Panics(t, func() { goroutiner.NewBulkhead(Cfg{Capacity: 1, MaxQueue: -1}) })
+		assert.Panics(t, func() { goroutiner.NewBulkhead(Cfg{Capacity: 1, AcquireTimeout: -1}) })
+		assert.Panics(t, func() { goroutiner.MwBulkhead(nil, 1) })
+		assert.Panics(t, func() { goroutiner.MwBulkhead(goroutiner.NewBulkhead(Cfg{Capacity: 2}), 0) })
+		assert.Panics(t, func() { goroutiner.MwBulkhead(goroutiner.NewBulkhead(Cfg{Capacity: 2}), 3) })
+	})
+
+	t.Run("capacity is shared across batches", func(t *testing.T) {
+		bh := goroutiner.NewBulkhead(Cfg{Capacity: 3})
+
+		mu := new(sync.Mutex)
+		inUse, maxInUse := int64(0), int64(0)
+
+		gWeighted := func(weight int64) goroutiner.Goroutine {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				inUse += weight
+				if inUse > maxInUse {
+					maxInUse = inUse
+				}
+				assert.Equal(t, inUse, bh.Stats().InUse)
+				mu.Unlock()
+
+				time.Sleep(5 * time.Millisecond)
+
+				mu.Lock()
+				inUse -= weight
+				mu.Unlock()
+				return nil
+			}
+		}
+
+		batch := func(grt *goroutiner.Goroutiner) []error {
+			return grt.Batch(ctx).
+				Add(gWeighted(1), goroutiner.MwBulkhead(bh, 1)).
+				Add(gWeighted(2), goroutiner.MwBulkhead(bh, 2)).
+				Add(gWeighted(3), goroutiner.MwBulkhead(bh, 3)).
+				Wait()
+		}
+
+		wg := new(sync.WaitGroup)
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Equal(t, make([]error, 3), batch(goroutiner.New()))
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(3), maxInUse)
+		assert.Equal(t, goroutiner.BulkheadStats{}, bh.Stats())
+	})
+
+	t.Run("queue limit and acquire timeout", func(t *testing.T) {
+		bh := goroutiner.NewBulkhead(Cfg{Capacity: 1, MaxQueue: 1, AcquireTimeout: 20 * time.Millisecond})
+		mw := goroutiner.MwBulkhead(bh, 1)
+
+		release := make(chan struct{})
+		holderStarted := make(chan struct{})
+
+		holder := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+			close(holderStarted)
+			<-release
+			return nil
+		}, mw).Start()
+		<-holderStarted
+
+		waiter := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error { return nil }, mw).Start()
+
+		// waits until the waiter is queued
+		for bh.Stats().Waiting == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		stats := bh.Stats()
+		assert.Equal(t, int64(1), stats.InUse)
+		assert.Equal(t, 1, stats.Waiting)
+
+		errs := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error { return nil }, mw).Wait()
+		assert.Equal(t, []error{goroutiner.ErrBulkheadFull}, errs)
+
+		assert.Equal(t, []error{goroutiner.ErrBulkheadTimeout}, waiter.Wait())
+
+		close(release)
+		assert.Equal(t, []error{nil}, holder.Wait())
+
+		assert.Equal(t, goroutiner.BulkheadStats{Rejected: 1, TimedOut: 1}, bh.Stats())
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		bh := goroutiner.NewBulkhead(Cfg{Capacity: 1})
+		mw := goroutiner.MwBulkhead(bh, 1)
+
+		release := make(chan struct{})
+		holderStarted := make(chan struct{})
+
+		holder := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+			close(holderStarted)
+			<-release
+			return nil
+		}, mw).Start()
+		<-holderStarted
+
+		executed := false
+
+		errs := goroutiner.New().Batch(ctx).Timeout(10*time.Millisecond).
+			Add(func(ctx context.Context) error {
+				executed = true
+				return nil
+			}, mw).
+			Wait()
+
+		assert.False(t, executed)
+		assert.Equal(t, []error{context.DeadlineExceeded}, errs)
+
+		close(release)
+		assert.Equal(t, []error{nil}, holder.Wait())
+
+		assert.Equal(t, goroutiner.BulkheadStats{}, bh.Stats())
+	})
+}
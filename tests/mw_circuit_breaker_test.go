@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Mw_CircuitBreaker(t *testing.T) {
+	type Cfg = goroutiner.CircuitBreakerConfig
+
+	ctx := context.TODO()
+	errFail := errors.New("fail")
+	gOk := func(ctx context.Context) error { return nil }
+	gFail := func(ctx context.Context) error { return errFail }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1, OpenDuration: time.Second})
+			goroutiner.NewCircuitBreaker(Cfg{FailureRate: 0.5, Window: time.Second, OpenDuration: time.Second})
+			goroutiner.MwCircuitBreaker(goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1, OpenDuration: time.Second}))
+		})
+		assert.Panics(t, func() { goroutiner.NewCircuitBreaker(Cfg{OpenDuration: time.Second}) })
+		assert.Panics(t, func() { goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: -1, OpenDuration: time.Second}) })
+		assert.Panics(t, func() { goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1}) })
+		assert.Panics(t, func() {
+			goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1, HalfOpenProbes: -1, OpenDuration: time.Second})
+		})
+		assert.Panics(t, func() { goroutiner.NewCircuitBreaker(Cfg{FailureRate: 0.5, OpenDuration: time.Second}) })
+		assert.Panics(t, func() {
+			goroutiner.NewCircuitBreaker(Cfg{FailureRate: 1.5, Window: time.Second, OpenDuration: time.Second})
+		})
+		assert.Panics(t, func() { goroutiner.MwCircuitBreaker(nil) })
+	})
+
+	t.Run("consecutive failures and recovery", func(t *testing.T) {
+		mu := new(sync.Mutex)
+		changes := make([]string, 0)
+
+		cb := goroutiner.NewCircuitBreaker(Cfg{
+			ConsecutiveFailures: 2,
+			OpenDuration:        10 * time.Millisecond,
+			HalfOpenProbes:      2,
+			FnOnStateChange: func(from goroutiner.CircuitState, to goroutiner.CircuitState) {
+				mu.Lock()
+				changes = append(changes, from.String()+"->"+to.String())
+				mu.Unlock()
+			},
+		})
+
+		// shared across batches and Goroutiner instances
+		grt1 := goroutiner.New(goroutiner.MwCircuitBreaker(cb))
+		grt2 := goroutiner.New(goroutiner.MwCircuitBreaker(cb))
+
+		assert.Equal(t, errFail, grt1.Batch(ctx).Add(gFail).Wait()[0])
+		assert.Equal(t, nil, grt2.Batch(ctx).Add(gOk).Wait()[0]) // resets consecutive failures
+		assert.Equal(t, errFail, grt1.Batch(ctx).Add(gFail).Wait()[0])
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+		assert.Equal(t, errFail, grt2.Batch(ctx).Add(gFail).Wait()[0])
+		assert.Equal(t, goroutiner.CircuitOpen, cb.State())
+
+		executed := false
+		err := grt1.Batch(ctx).Add(func(ctx context.Context) error {
+			executed = true
+			return nil
+		}).Wait()[0]
+		assert.Equal(t, goroutiner.ErrCircuitOpen, err)
+		assert.False(t, executed)
+
+		time.Sleep(15 * time.Millisecond)
+		assert.Equal(t, goroutiner.CircuitHalfOpen, cb.State())
+
+		// probes: failure re-opens the circuit
+		assert.Equal(t, errFail, grt1.Batch(ctx).Add(gFail).Wait()[0])
+		assert.Equal(t, goroutiner.CircuitOpen, cb.State())
+
+		time.Sleep(15 * time.Millisecond)
+
+		// probes: all succeeded -- closes the circuit
+		assert.Equal(t, nil, grt1.Batch(ctx).Add(gOk).Wait()[0])
+		assert.Equal(t, goroutiner.CircuitHalfOpen, cb.State())
+		assert.Equal(t, nil, grt2.Batch(ctx).Add(gOk).Wait()[0])
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+
+		assert.Equal(t, []string{
+			"closed->open",
+			"open->half-open",
+			"half-open->open",
+			"open->half-open",
+			"half-open->closed",
+		}, changes)
+	})
+
+	t.Run("half-open probes limit", func(t *testing.T) {
+		cb := goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1, OpenDuration: time.Millisecond})
+		mw := goroutiner.MwCircuitBreaker(cb)
+
+		_ = goroutiner.New().Batch(ctx).Add(gFail, mw).Wait()
+		time.Sleep(5 * time.Millisecond)
+
+		probeStarted := make(chan struct{})
+		probeRelease := make(chan struct{})
+
+		errs := goroutiner.New().Batch(ctx).
+			Add(func(ctx context.Context) error {
+				close(probeStarted)
+				<-probeRelease
+				return nil
+			}, mw).
+			Add(gOk, func(g goroutiner.Goroutine) goroutiner.Goroutine {
+				// passes through the breaker only while the first probe is running
+				return func(ctx context.Context) error {
+					<-probeStarted
+					defer close(probeRelease)
+					return g(ctx)
+				}
+			}, mw).
+			Wait()
+
+		assert.Equal(t, []error{nil, goroutiner.ErrCircuitOpen}, errs)
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+	})
+
+	t.Run("stale probes are ignored", func(t *testing.T) {
+		cb := goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1, OpenDuration: 5 * time.Millisecond, HalfOpenProbes: 2})
+		mw := goroutiner.MwCircuitBreaker(cb)
+
+		_ = goroutiner.New().Batch(ctx).Add(gFail, mw).Wait()
+		time.Sleep(10 * time.Millisecond)
+
+		probeStarted := make(chan struct{})
+		probeRelease := make(chan struct{})
+
+		h := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+			close(probeStarted)
+			<-probeRelease
+			return nil
+		}, mw).Start()
+		<-probeStarted
+
+		// another probe fails, the circuit is opened and then half-opened again, while the first probe is running
+		_ = goroutiner.New().Batch(ctx).Add(gFail, mw).Wait()
+		assert.Equal(t, goroutiner.CircuitOpen, cb.State())
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, goroutiner.CircuitHalfOpen, cb.State())
+
+		close(probeRelease)
+		assert.Equal(t, []error{nil}, h.Wait())
+
+		// the stale probe neither takes a probe slot, nor is counted as a succeeded probe
+		assert.Equal(t, []error{nil}, goroutiner.New().Batch(ctx).Add(gOk, mw).Wait())
+		assert.Equal(t, goroutiner.CircuitHalfOpen, cb.State())
+		assert.Equal(t, []error{nil}, goroutiner.New().Batch(ctx).Add(gOk, mw).Wait())
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+	})
+
+	t.Run("failure rate", func(t *testing.T) {
+		cb := goroutiner.NewCircuitBreaker(Cfg{
+			FailureRate:  0.5,
+			Window:       time.Second,
+			MinRequests:  4,
+			OpenDuration: time.Second,
+		})
+		mw := goroutiner.MwCircuitBreaker(cb)
+
+		_ = goroutiner.New().Batch(ctx).Add(gFail, mw).Wait()
+		_ = goroutiner.New().Batch(ctx).Add(gFail, mw).Wait()
+		_ = goroutiner.New().Batch(ctx).Add(gOk, mw).Wait()
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State()) // min requests not reached
+
+		_ = goroutiner.New().Batch(ctx).Add(gOk, mw).Wait()
+		assert.Equal(t, goroutiner.CircuitOpen, cb.State()) // 2 of 4
+	})
+
+	t.Run("failure predicate", func(t *testing.T) {
+		errIgnored := fmt.Errorf("ignored")
+
+		cb := goroutiner.NewCircuitBreaker(Cfg{
+			ConsecutiveFailures: 1,
+			OpenDuration:        time.Second,
+			FnIsFailure:         func(err error) bool { return !errors.Is(err, errIgnored) },
+		})
+		mw := goroutiner.MwCircuitBreaker(cb)
+
+		_ = goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error { return errIgnored }, mw).Wait()
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+
+		// the predicate receives errors only -- success is never a failure
+		_ = goroutiner.New().Batch(ctx).Add(gOk, mw).Wait()
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+
+		// context cancellation is not a failure by default
+		cb = goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1, OpenDuration: time.Second})
+		_ = goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error { return context.Canceled }, goroutiner.MwCircuitBreaker(cb)).Wait()
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+	})
+
+	t.Run("ignored errors", func(t *testing.T) {
+		cb := goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 3, OpenDuration: 5 * time.Millisecond})
+		mw := goroutiner.MwCircuitBreaker(cb)
+
+		gCancelled := func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		// siblings cancelled by the failure neither reset consecutive failures, nor count as successes
+		for i := 0; i < 3; i++ {
+			err := goroutiner.New().Batch(ctx, mw).Add(gFail).Add(gCancelled).CancelOnError()
+			assert.Equal(t, errFail, err)
+		}
+		assert.Equal(t, goroutiner.CircuitOpen, cb.State())
+
+		// the ignored probe releases its slot, but does not close the circuit
+		time.Sleep(10 * time.Millisecond)
+		errs := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error { return context.Canceled }, mw).Wait()
+		assert.Equal(t, []error{context.Canceled}, errs)
+		assert.Equal(t, goroutiner.CircuitHalfOpen, cb.State())
+
+		assert.Equal(t, []error{nil}, goroutiner.New().Batch(ctx).Add(gOk, mw).Wait())
+		assert.Equal(t, goroutiner.CircuitClosed, cb.State())
+	})
+
+	t.Run("panic is a failure", func(t *testing.T) {
+		cb := goroutiner.NewCircuitBreaker(Cfg{ConsecutiveFailures: 1, OpenDuration: time.Second})
+
+		mwPanicToError := goroutiner.MwPanicToError(func(panicValue any, debugStack []byte, ctx context.Context) error {
+			return fmt.Errorf("%v", panicValue)
+		})
+
+		err := goroutiner.New(mwPanicToError, goroutiner.MwCircuitBreaker(cb)).Batch(ctx).
+			Add(func(ctx context.Context) error { panic("cb") }).
+			Wait()[0]
+
+		assert.EqualError(t, err, "cb")
+		assert.Equal(t, goroutiner.CircuitOpen, cb.State())
+	})
+}
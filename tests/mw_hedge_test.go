@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Mw_Hedge(t *testing.T) {
+	type Cfg = goroutiner.HedgeConfig
+
+	ctx := context.TODO()
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 1})
+			goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 2, Percentile: 0.95, MinSamples: 10, MaxSamples: 10})
+		})
+		assert.Panics(t, func() { goroutiner.MwHedge(Cfg{MaxHedges: 1}) })
+		assert.Panics(t, func() { goroutiner.MwHedge(Cfg{Delay: time.Millisecond}) })
+		assert.Panics(t, func() { goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 1, Percentile: -0.1}) })
+		assert.Panics(t, func() { goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 1, Percentile: 1}) })
+		assert.Panics(t, func() { goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 1, MinSamples: -1}) })
+		assert.Panics(t, func() { goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 1, MaxSamples: -1}) })
+		assert.Panics(t, func() {
+			goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 1, MinSamples: 11, MaxSamples: 10})
+		})
+	})
+
+	// hedged returns a goroutine, which `attempt`-th call takes `durations[attempt]` and returns `errs[attempt]`.
+	// Cancelled attempts are counted.
+	hedged := func(durations []time.Duration, errs []error, cancelled *int64) goroutiner.Goroutine {
+		var calls int64
+
+		return func(ctx context.Context) error {
+			attempt := atomic.AddInt64(&calls, 1) - 1
+
+			select {
+			case <-ctx.Done():
+				atomic.AddInt64(cancelled, 1)
+				return ctx.Err()
+			case <-time.After(durations[attempt]):
+				return errs[attempt]
+			}
+		}
+	}
+
+	t.Run("fast original attempt", func(t *testing.T) {
+		hedges := int64(0)
+
+		mw := goroutiner.MwHedge(Cfg{
+			Delay:     20 * time.Millisecond,
+			MaxHedges: 2,
+			FnOnHedge: func(attempt int, ctx context.Context) { atomic.AddInt64(&hedges, 1) },
+		})
+
+		errs := goroutiner.New().Batch(ctx).Add(g, mw).Wait()
+
+		assert.Equal(t, []error{nil}, errs)
+		assert.Zero(t, atomic.LoadInt64(&hedges))
+	})
+
+	t.Run("hedge wins and others are cancelled", func(t *testing.T) {
+		var cancelled int64
+		winner := -1
+		hedgeAttempts := make([]int, 0)
+
+		mw := goroutiner.MwHedge(Cfg{
+			Delay:     5 * time.Millisecond,
+			MaxHedges: 2,
+			FnOnHedge: func(attempt int, ctx context.Context) { hedgeAttempts = append(hedgeAttempts, attempt) },
+			FnOnWin: func(attempt int, latency time.Duration, ctx context.Context) {
+				winner = attempt
+				assert.Less(t, latency, 20*time.Millisecond)
+			},
+		})
+
+		start := time.Now()
+
+		errs := goroutiner.New().Batch(ctx).
+			Add(hedged([]time.Duration{time.Second, 2 * time.Millisecond, time.Second}, make([]error, 3), &cancelled), mw).
+			Wait()
+
+		assert.Equal(t, []error{nil}, errs)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		assert.Equal(t, 1, winner)
+		assert.Equal(t, []int{1}, hedgeAttempts)
+
+		// the original attempt is cancelled in background
+		for atomic.LoadInt64(&cancelled) != 1 {
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("failed attempts are hedged immediately", func(t *testing.T) {
+		var cancelled int64
+		errFirst := errors.New("first")
+		errLast := errors.New("last")
+
+		mw := goroutiner.MwHedge(Cfg{Delay: time.Second, MaxHedges: 2})
+
+		start := time.Now()
+
+		errs := goroutiner.New().Batch(ctx).
+			Add(hedged(make([]time.Duration, 3), []error{errFirst, errFirst, errLast}, &cancelled), mw).
+			Wait()
+
+		assert.Equal(t, []error{errLast}, errs)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		assert.Zero(t, atomic.LoadInt64(&cancelled))
+	})
+
+	t.Run("percentile delay", func(t *testing.T) {
+		mu := new(sync.Mutex)
+		hedges := 0
+
+		mw := goroutiner.MwHedge(Cfg{
+			Delay:      time.Second,
+			MaxHedges:  1,
+			Percentile: 0.5,
+			MinSamples: 3,
+			FnOnHedge: func(attempt int, ctx context.Context) {
+				mu.Lock()
+				hedges++
+				mu.Unlock()
+			},
+		})
+
+		// collects latencies without hedging (the static delay is long)
+		for i := 0; i < 3; i++ {
+			goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+				time.Sleep(time.Millisecond)
+				return nil
+			}, mw).Wait()
+		}
+		assert.Zero(t, hedges)
+
+		// the delay is ~1ms now
+		var cancelled int64
+		errs := goroutiner.New().Batch(ctx).
+			Add(hedged([]time.Duration{time.Second, 0}, make([]error, 2), &cancelled), mw).
+			Wait()
+
+		assert.Equal(t, []error{nil}, errs)
+		assert.Equal(t, 1, hedges)
+	})
+
+	t.Run("percentile delay does not drift to winning hedges", func(t *testing.T) {
+		var hedges, cancelled int64
+
+		mw := goroutiner.MwHedge(Cfg{
+			Delay:      time.Second,
+			MaxHedges:  1,
+			Percentile: 0.5,
+			MaxSamples: 1,
+			FnOnHedge:  func(attempt int, ctx context.Context) { atomic.AddInt64(&hedges, 1) },
+		})
+
+		// the original latency is observed: the delay is ~20ms
+		errs := goroutiner.New().Batch(ctx).Add(hedged([]time.Duration{20 * time.Millisecond}, []error{nil}, &cancelled), mw).Wait()
+		assert.Equal(t, []error{nil}, errs)
+		assert.Zero(t, atomic.LoadInt64(&hedges))
+
+		// the hedge wins immediately, but the time elapsed since the original start (~20ms) is observed
+		errs = goroutiner.New().Batch(ctx).Add(hedged([]time.Duration{time.Second, 0}, make([]error, 2), &cancelled), mw).Wait()
+		assert.Equal(t, []error{nil}, errs)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&hedges))
+
+		// the fast original attempt is not hedged
+		errs = goroutiner.New().Batch(ctx).Add(hedged([]time.Duration{5 * time.Millisecond, 0}, make([]error, 2), &cancelled), mw).Wait()
+		assert.Equal(t, []error{nil}, errs)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&hedges))
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		var cancelled, hedges int64
+
+		mw := goroutiner.MwHedge(Cfg{
+			Delay:     5 * time.Millisecond,
+			MaxHedges: 5,
+			FnOnHedge: func(attempt int, ctx context.Context) { atomic.AddInt64(&hedges, 1) },
+		})
+
+		durations := []time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second, time.Second}
+
+		errs := goroutiner.New().Batch(ctx).Timeout(12*time.Millisecond).
+			Add(hedged(durations, make([]error, 6), &cancelled), mw).
+			Wait()
+
+		assert.Equal(t, []error{context.DeadlineExceeded}, errs)
+		// no hedges are started after the context is done, and all started attempts are awaited
+		assert.Less(t, atomic.LoadInt64(&hedges), int64(5))
+		assert.Equal(t, atomic.LoadInt64(&hedges)+1, atomic.LoadInt64(&cancelled))
+	})
+
+	t.Run("panics are propagated", func(t *testing.T) {
+		mw := goroutiner.MwHedge(Cfg{Delay: time.Millisecond, MaxHedges: 1})
+
+		assert.PanicsWithValue(t, "hedge panic", func() {
+			_ = mw(func(ctx context.Context) error { panic("hedge panic") })(ctx)
+		})
+	})
+}
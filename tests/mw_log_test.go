@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testLogRecord struct {
+	level string
+	msg   string
+	attrs map[string]any
+}
+
+// testLogger collects records, implementing the same methods as *slog.Logger.
+type testLogger struct {
+	mu      sync.Mutex
+	records []testLogRecord
+}
+
+func (l *testLogger) log(level string, msg string, args ...any) {
+	attrs := make(map[string]any)
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+
+	l.mu.Lock()
+	l.records = append(l.records, testLogRecord{level: level, msg: msg, attrs: attrs})
+	l.mu.Unlock()
+}
+
+func (l *testLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.log("debug", msg, args...)
+}
+
+func (l *testLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.log("info", msg, args...)
+}
+
+func (l *testLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.log("warn", msg, args...)
+}
+
+func (l *testLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.log("error", msg, args...)
+}
+
+func (l *testLogger) levelsAndMessages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lms := make([]string, len(l.records))
+	for i, r := range l.records {
+		lms[i] = r.level + ": " + r.msg
+	}
+
+	return lms
+}
+
+func Test_Mw_Log(t *testing.T) {
+	type Cfg = goroutiner.LogConfig
+
+	ctx := context.TODO()
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		logger := new(testLogger)
+
+		assert.NotPanics(t, func() {
+			goroutiner.MwLog(Cfg{Logger: logger})
+			goroutiner.MwLog(Cfg{
+				Logger:        logger,
+				StartLevel:    goroutiner.LogOff,
+				SuccessLevel:  goroutiner.LogInfo,
+				ErrorLevel:    goroutiner.LogWarn,
+				PanicLevel:    goroutiner.LogError,
+				SlowLevel:     goroutiner.LogDebug,
+				SlowThreshold: time.Second,
+				SampleEvery:   10,
+			})
+		})
+		assert.Panics(t, func() { goroutiner.MwLog(Cfg{}) })
+		assert.Panics(t, func() { goroutiner.MwLog(Cfg{Logger: logger, StartLevel: goroutiner.LogLevel(-1)}) })
+		assert.Panics(t, func() { goroutiner.MwLog(Cfg{Logger: logger, SlowLevel: goroutiner.LogOff + 1}) })
+		assert.Panics(t, func() { goroutiner.MwLog(Cfg{Logger: logger, SlowThreshold: -1}) })
+		assert.Panics(t, func() { goroutiner.MwLog(Cfg{Logger: logger, SampleEvery: -1}) })
+	})
+
+	t.Run("default levels and attributes", func(t *testing.T) {
+		type ctxKey struct{}
+
+		logger := new(testLogger)
+		errTest := errors.New("test")
+
+		mw := goroutiner.MwLog(Cfg{
+			Logger: logger,
+			FnAttrs: func(ctx context.Context) []any {
+				return []any{"request.id", ctx.Value(ctxKey{})}
+			},
+		})
+
+		ctx := context.WithValue(ctx, ctxKey{}, "req-1")
+
+		errs := goroutiner.New(mw).Batch(ctx).Limit(1).
+			AddNamed("first", g).
+			AddWith(func(ctx context.Context) error { return errTest }, goroutiner.WithLabels(map[string]string{"k": "v"})).
+			Wait()
+
+		assert.Equal(t, []error{nil, errTest}, errs)
+		assert.Equal(t, []string{
+			"debug: goroutine started",
+			"debug: goroutine succeeded",
+			"debug: goroutine started",
+			"error: goroutine failed",
+		}, logger.levelsAndMessages())
+
+		started := logger.records[0].attrs
+		assert.Equal(t, 0, started["goroutine.index"])
+		assert.Equal(t, "first", started["goroutine.name"])
+		assert.NotZero(t, started["batch.id"])
+		assert.Equal(t, "Wait", started["batch.strategy"])
+		assert.Equal(t, "req-1", started["request.id"])
+		assert.NotContains(t, started, "duration")
+
+		assert.Contains(t, logger.records[1].attrs, "duration")
+
+		failed := logger.records[3].attrs
+		assert.Equal(t, 1, failed["goroutine.index"])
+		assert.NotContains(t, failed, "goroutine.name")
+		assert.Equal(t, map[string]string{"k": "v"}, failed["goroutine.labels"])
+		assert.Equal(t, errTest, failed["error"])
+		assert.Equal(t, "req-1", failed["request.id"])
+
+		// without GoroutineInfo (i.e. not executed by a Batch)
+		logger.records = nil
+		_ = goroutiner.MwLog(Cfg{Logger: logger})(g)(context.TODO())
+		require.Len(t, logger.records, 2)
+		assert.NotContains(t, logger.records[0].attrs, "goroutine.index")
+	})
+
+	t.Run("custom levels and slow completion", func(t *testing.T) {
+		logger := new(testLogger)
+
+		mw := goroutiner.MwLog(Cfg{
+			Logger:        logger,
+			StartLevel:    goroutiner.LogOff,
+			SuccessLevel:  goroutiner.LogInfo,
+			SlowThreshold: 5 * time.Millisecond,
+		})
+
+		goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}, mw).Wait()
+
+		assert.Equal(t, []string{"info: goroutine succeeded", "warn: goroutine slow"}, logger.levelsAndMessages())
+		assert.Equal(t, 5*time.Millisecond, logger.records[1].attrs["threshold"])
+		assert.GreaterOrEqual(t, logger.records[1].attrs["duration"], 5*time.Millisecond)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		logger := new(testLogger)
+		mw := goroutiner.MwLog(Cfg{Logger: logger, StartLevel: goroutiner.LogOff})
+
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = mw(func(ctx context.Context) error { panic("boom") })(ctx)
+		})
+
+		assert.Equal(t, []string{"error: goroutine panicked"}, logger.levelsAndMessages())
+		assert.Equal(t, "boom", logger.records[0].attrs["panic"])
+		assert.Contains(t, logger.records[0].attrs["stack"], "goroutine")
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		logger := new(testLogger)
+		errTest := errors.New("test")
+
+		mw := goroutiner.MwLog(Cfg{Logger: logger, SampleEvery: 3})
+
+		for i := 0; i < 6; i++ {
+			_ = mw(g)(ctx)
+		}
+		// failures are always logged
+		_ = mw(func(ctx context.Context) error { return errTest })(ctx)
+		_ = mw(func(ctx context.Context) error { return errTest })(ctx)
+
+		assert.Equal(t, []string{
+			"debug: goroutine started",
+			"debug: goroutine succeeded",
+			"debug: goroutine started",
+			"debug: goroutine succeeded",
+			"debug: goroutine started",
+			"error: goroutine failed",
+			"error: goroutine failed",
+		}, logger.levelsAndMessages())
+	})
+}
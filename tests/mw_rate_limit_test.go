@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Mw_RateLimit(t *testing.T) {
+	type Cfg = goroutiner.RateLimiterConfig
+
+	ctx := context.TODO()
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			rl := goroutiner.NewRateLimiter(Cfg{Rate: 0.5, Burst: 1})
+			goroutiner.MwRateLimit(rl, goroutiner.RateLimitWait)
+			goroutiner.MwRateLimit(rl, goroutiner.RateLimitFailFast)
+		})
+		assert.Panics(t, func() { goroutiner.NewRateLimiter(Cfg{Burst: 1}) })
+		assert.Panics(t, func() { goroutiner.NewRateLimiter(Cfg{Rate: -1, Burst: 1}) })
+		assert.Panics(t, func() { goroutiner.NewRateLimiter(Cfg{Rate: 1}) })
+		assert.Panics(t, func() { goroutiner.MwRateLimit(nil, goroutiner.RateLimitWait) })
+		assert.Panics(t, func() {
+			goroutiner.MwRateLimit(goroutiner.NewRateLimiter(Cfg{Rate: 1, Burst: 1}), goroutiner.RateLimitPolicy(-1))
+		})
+	})
+
+	t.Run("burst and waiting", func(t *testing.T) {
+		rl := goroutiner.NewRateLimiter(Cfg{Rate: 100, Burst: 3})
+		mw := goroutiner.MwRateLimit(rl, goroutiner.RateLimitWait)
+
+		mu := new(sync.Mutex)
+		startedAt := make([]time.Duration, 0)
+		start := time.Now()
+
+		gRecord := func(ctx context.Context) error {
+			mu.Lock()
+			startedAt = append(startedAt, time.Since(start))
+			mu.Unlock()
+			return nil
+		}
+
+		// the limiter is shared by different batches
+		grt := goroutiner.New(mw)
+		errs1 := grt.Batch(ctx).Add(gRecord).Add(gRecord).Add(gRecord).Wait()
+		errs2 := grt.Batch(ctx).Add(gRecord).Add(gRecord).Wait()
+
+		assert.Equal(t, make([]error, 3), errs1)
+		assert.Equal(t, make([]error, 2), errs2)
+
+		// burst goroutines started immediately, others waited for 10ms each
+		for _, d := range startedAt[:3] {
+			assert.Less(t, d, 10*time.Millisecond)
+		}
+		assert.GreaterOrEqual(t, startedAt[4], 19*time.Millisecond)
+
+		stats := rl.Stats()
+		assert.Equal(t, uint64(5), stats.Allowed)
+		assert.Zero(t, stats.Waiting)
+		assert.Zero(t, stats.Rejected)
+		assert.Zero(t, stats.Cancelled)
+		assert.GreaterOrEqual(t, stats.TotalWait, 25*time.Millisecond)
+		assert.Less(t, stats.Tokens, 1.0)
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		rl := goroutiner.NewRateLimiter(Cfg{Rate: 1, Burst: 2})
+
+		errs := goroutiner.New().Batch(ctx).Limit(1).
+			Add(g).
+			Add(g).
+			Add(g).
+			Add(g, goroutiner.MwRateLimit(rl, goroutiner.RateLimitFailFast)).
+			Add(g, goroutiner.MwRateLimit(rl, goroutiner.RateLimitFailFast)).
+			Add(g, goroutiner.MwRateLimit(rl, goroutiner.RateLimitFailFast)).
+			Wait()
+
+		assert.Equal(t, []error{nil, nil, nil, nil, nil, goroutiner.ErrRateLimited}, errs)
+
+		stats := rl.Stats()
+		assert.Equal(t, uint64(2), stats.Allowed)
+		assert.Equal(t, uint64(1), stats.Rejected)
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		rl := goroutiner.NewRateLimiter(Cfg{Rate: 1, Burst: 1})
+		mw := goroutiner.MwRateLimit(rl, goroutiner.RateLimitWait)
+
+		executed := 0
+
+		errs := goroutiner.New(mw).Batch(ctx).Limit(1).Timeout(10 * time.Millisecond).
+			Add(func(ctx context.Context) error {
+				executed++
+				return nil
+			}).
+			Add(func(ctx context.Context) error {
+				executed++
+				return nil
+			}).
+			Wait()
+
+		assert.Equal(t, 1, executed)
+		assert.Equal(t, []error{nil, context.DeadlineExceeded}, errs)
+
+		stats := rl.Stats()
+		assert.Equal(t, uint64(1), stats.Allowed)
+		assert.Equal(t, uint64(1), stats.Cancelled)
+		assert.Zero(t, stats.Waiting)
+		// the reserved token is returned
+		assert.GreaterOrEqual(t, stats.Tokens, 0.0)
+	})
+}
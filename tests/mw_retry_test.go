@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_Mw_Retry(t *testing.T) {
+	type Cfg = goroutiner.RetryConfig
+
+	ctx := context.TODO()
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.MwRetry(Cfg{MaxAttempts: 1})
+			goroutiner.MwRetry(Cfg{MaxElapsed: time.Second})
+			goroutiner.MwRetry(Cfg{MaxAttempts: 3, Multiplier: 1, Jitter: 1})
+		})
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{}) })
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{MaxAttempts: -1}) })
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{MaxAttempts: 1, InitialBackoff: -1}) })
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{MaxAttempts: 1, MaxBackoff: -1}) })
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{MaxAttempts: 1, MaxElapsed: -1}) })
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{MaxAttempts: 1, Multiplier: 0.5}) })
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{MaxAttempts: 1, Jitter: -0.1}) })
+		assert.Panics(t, func() { goroutiner.MwRetry(Cfg{MaxAttempts: 1, Jitter: 1.1}) })
+	})
+
+	// mG fails `n` times, then succeeds.
+	mG := func(n int, attempts *int) goroutiner.Goroutine {
+		return func(ctx context.Context) error {
+			*attempts++
+			if *attempts <= n {
+				return fmt.Errorf("error #%d", *attempts)
+			}
+			return nil
+		}
+	}
+
+	t.Run("success after retries", func(t *testing.T) {
+		attempts := 0
+		err := goroutiner.New().Batch(ctx).Add(mG(2, &attempts), goroutiner.MwRetry(Cfg{MaxAttempts: 3})).Wait()[0]
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		attempts := 0
+		err := goroutiner.New().Batch(ctx).Add(mG(5, &attempts), goroutiner.MwRetry(Cfg{MaxAttempts: 3})).Wait()[0]
+
+		assert.Equal(t, 3, attempts)
+
+		var retryErr *goroutiner.RetryError
+		require.True(t, errors.As(err, &retryErr))
+		assert.Equal(t, []error{fmt.Errorf("error #1"), fmt.Errorf("error #2"), fmt.Errorf("error #3")}, retryErr.Errors)
+		assert.Nil(t, retryErr.Interrupted)
+		assert.EqualError(t, errors.Unwrap(err), "error #3")
+	})
+
+	t.Run("errors traversal", func(t *testing.T) {
+		errFirst := errors.New("first")
+		attempt := 0
+
+		err := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+			attempt++
+			if attempt == 1 {
+				return errFirst
+			}
+			return errors.New("other")
+		}, goroutiner.MwRetry(Cfg{MaxAttempts: 2})).Wait()[0]
+
+		assert.ErrorIs(t, err, errFirst)
+	})
+
+	t.Run("non-retryable error", func(t *testing.T) {
+		errFatal := errors.New("fatal")
+		attempts := 0
+
+		err := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+			attempts++
+			return errFatal
+		}, goroutiner.MwRetry(Cfg{
+			MaxAttempts: 5,
+			FnRetryable: func(err error) bool { return !errors.Is(err, errFatal) },
+		})).Wait()[0]
+
+		assert.Equal(t, 1, attempts)
+		assert.ErrorIs(t, err, errFatal)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		delays := make([]time.Duration, 0)
+		attempts := 0
+
+		_ = goroutiner.New().Batch(ctx).Add(mG(10, &attempts), goroutiner.MwRetry(Cfg{
+			MaxAttempts:    6,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+			FnOnRetry: func(attempt int, err error, delay time.Duration, ctx context.Context) {
+				assert.Equal(t, len(delays)+1, attempt)
+				assert.EqualError(t, err, fmt.Sprintf("error #%d", attempt))
+				delays = append(delays, delay)
+			},
+		})).Wait()
+
+		ms := time.Millisecond
+		assert.Equal(t, []time.Duration{1 * ms, 2 * ms, 4 * ms, 8 * ms, 10 * ms}, delays)
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		attempts := 0
+
+		_ = goroutiner.New().Batch(ctx).Add(mG(10, &attempts), goroutiner.MwRetry(Cfg{
+			MaxAttempts:    10,
+			InitialBackoff: 100 * time.Microsecond,
+			Multiplier:     1,
+			Jitter:         0.5,
+			FnOnRetry: func(attempt int, err error, delay time.Duration, ctx context.Context) {
+				assert.GreaterOrEqual(t, delay, 50*time.Microsecond)
+				assert.LessOrEqual(t, delay, 150*time.Microsecond)
+			},
+		})).Wait()
+
+		assert.Equal(t, 10, attempts)
+	})
+
+	t.Run("max elapsed", func(t *testing.T) {
+		attempts := 0
+
+		err := goroutiner.New().Batch(ctx).Add(mG(100, &attempts), goroutiner.MwRetry(Cfg{
+			InitialBackoff: 20 * time.Millisecond,
+			Multiplier:     1,
+			MaxElapsed:     50 * time.Millisecond,
+		})).Wait()[0]
+
+		assert.Equal(t, 3, attempts)
+		assert.Error(t, err)
+	})
+
+	t.Run("context cancellation aborts waiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		attempts := 0
+		start := time.Now()
+
+		err := goroutiner.New().Batch(ctx).Add(mG(100, &attempts), goroutiner.MwRetry(Cfg{
+			MaxAttempts:    3,
+			InitialBackoff: time.Second,
+		})).Wait()[0]
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, 1, attempts)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var retryErr *goroutiner.RetryError
+		require.True(t, errors.As(err, &retryErr))
+		assert.Equal(t, context.DeadlineExceeded, retryErr.Interrupted)
+	})
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Mw_Singleflight(t *testing.T) {
+	ctx := context.TODO()
+	g := func(ctx context.Context) error { return nil }
+	fnKey := func(ctx context.Context) string { return "key" }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.MwSingleflight(goroutiner.NewSingleflight(goroutiner.SingleflightCancelWithLeader), fnKey)
+			goroutiner.MwSingleflightKey(goroutiner.NewSingleflight(goroutiner.SingleflightCancelWithAll), "key")
+		})
+		assert.Panics(t, func() { goroutiner.NewSingleflight(goroutiner.SingleflightCancelPolicy(-1)) })
+		assert.Panics(t, func() { goroutiner.MwSingleflight(nil, fnKey) })
+		assert.Panics(t, func() {
+			goroutiner.MwSingleflight(goroutiner.NewSingleflight(goroutiner.SingleflightCancelWithLeader), nil)
+		})
+		assert.Panics(t, func() { goroutiner.MwSingleflightKey(nil, "key") })
+		assert.Panics(t, func() {
+			goroutiner.MwSingleflightKey(goroutiner.NewSingleflight(goroutiner.SingleflightCancelWithLeader), "")
+		})
+	})
+
+	// slow returns a goroutine counting its executions, which returns `err` after `release` is closed.
+	slow := func(executions *int64, release <-chan struct{}, err error) goroutiner.Goroutine {
+		return func(ctx context.Context) error {
+			atomic.AddInt64(executions, 1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-release:
+				return err
+			}
+		}
+	}
+
+	// waitShared waits until `n` callers joined running executions.
+	waitShared := func(sf *goroutiner.Singleflight, n uint64) {
+		for sf.Stats().Shared < n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	for name, policy := range map[string]goroutiner.SingleflightCancelPolicy{
+		"leader policy": goroutiner.SingleflightCancelWithLeader,
+		"all policy":    goroutiner.SingleflightCancelWithAll,
+	} {
+		t.Run("deduplication with "+name, func(t *testing.T) {
+			sf := goroutiner.NewSingleflight(policy)
+			errTest := errors.New("test")
+
+			var executions int64
+			release := make(chan struct{})
+
+			// different batches and goroutiner instances share the same execution
+			mw := goroutiner.MwSingleflightKey(sf, "key")
+			h1 := goroutiner.New().Batch(ctx).Add(slow(&executions, release, errTest), mw).Start()
+			for atomic.LoadInt64(&executions) != 1 {
+				time.Sleep(time.Millisecond)
+			}
+			h2 := goroutiner.New().Batch(ctx, mw).Add(g).Add(slow(&executions, release, nil)).Start()
+
+			waitShared(sf, 2)
+			assert.Equal(t, goroutiner.SingleflightStats{InFlight: 1, Executions: 1, Shared: 2}, sf.Stats())
+
+			// other keys are executed independently
+			assert.Equal(t, []error{nil}, goroutiner.New().Batch(ctx).Add(g, goroutiner.MwSingleflightKey(sf, "other")).Wait())
+			// empty keys are not deduplicated
+			assert.Equal(t, []error{nil}, goroutiner.New().Batch(ctx).Add(g, goroutiner.MwSingleflight(sf, func(ctx context.Context) string { return "" })).Wait())
+
+			close(release)
+
+			assert.Equal(t, []error{errTest}, h1.Wait())
+			assert.Equal(t, []error{errTest, errTest}, h2.Wait())
+			assert.Equal(t, int64(1), atomic.LoadInt64(&executions))
+			assert.Equal(t, goroutiner.SingleflightStats{InFlight: 0, Executions: 2, Shared: 2}, sf.Stats())
+
+			// completed keys are executed again
+			assert.Equal(t, []error{nil}, goroutiner.New().Batch(ctx).Add(g, mw).Wait())
+			assert.Equal(t, uint64(3), sf.Stats().Executions)
+		})
+
+		t.Run("forget with "+name, func(t *testing.T) {
+			sf := goroutiner.NewSingleflight(policy)
+			mw := goroutiner.MwSingleflight(sf, fnKey)
+
+			var executions int64
+			release1 := make(chan struct{})
+			release2 := make(chan struct{})
+
+			h1 := goroutiner.New().Batch(ctx).Add(slow(&executions, release1, nil), mw).Start()
+			for atomic.LoadInt64(&executions) != 1 {
+				time.Sleep(time.Millisecond)
+			}
+
+			sf.Forget("key")
+
+			errTest := errors.New("test")
+			h2 := goroutiner.New().Batch(ctx).Add(slow(&executions, release2, errTest), mw).Start()
+			for atomic.LoadInt64(&executions) != 2 {
+				time.Sleep(time.Millisecond)
+			}
+
+			// forgotten execution must not remove the new one
+			close(release1)
+			assert.Equal(t, []error{nil}, h1.Wait())
+			assert.Equal(t, 1, sf.Stats().InFlight)
+
+			close(release2)
+			assert.Equal(t, []error{errTest}, h2.Wait())
+			assert.Equal(t, goroutiner.SingleflightStats{Executions: 2}, sf.Stats())
+		})
+
+		t.Run("panics are propagated to all callers with "+name, func(t *testing.T) {
+			sf := goroutiner.NewSingleflight(policy)
+			mw := goroutiner.MwSingleflight(sf, fnKey)
+			mwPanicToError := goroutiner.MwPanicToError(func(panicValue any, debugStack []byte, ctx context.Context) error {
+				return errors.New(panicValue.(string))
+			})
+
+			release := make(chan struct{})
+			executions := int64(0)
+
+			grt := goroutiner.New(mwPanicToError, mw)
+
+			h1 := grt.Batch(ctx).
+				Add(func(ctx context.Context) error {
+					atomic.AddInt64(&executions, 1)
+					<-release
+					panic("boom")
+				}).
+				Start()
+			for atomic.LoadInt64(&executions) != 1 {
+				time.Sleep(time.Millisecond)
+			}
+			h2 := grt.Batch(ctx).Add(g).Start()
+
+			waitShared(sf, 1)
+			close(release)
+
+			assert.EqualError(t, h1.Wait()[0], "boom")
+			assert.EqualError(t, h2.Wait()[0], "boom")
+			assert.Equal(t, int64(1), atomic.LoadInt64(&executions))
+		})
+	}
+
+	t.Run("leader cancellation with leader policy", func(t *testing.T) {
+		sf := goroutiner.NewSingleflight(goroutiner.SingleflightCancelWithLeader)
+		mw := goroutiner.MwSingleflight(sf, fnKey)
+
+		var executions int64
+		leaderCtx, cancelLeader := context.WithCancel(ctx)
+		defer cancelLeader()
+
+		h1 := goroutiner.New().Batch(leaderCtx).Add(slow(&executions, nil, nil), mw).Start()
+		for atomic.LoadInt64(&executions) != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		h2 := goroutiner.New().Batch(ctx).Add(g, mw).Start()
+		waitShared(sf, 1)
+
+		cancelLeader()
+
+		assert.Equal(t, []error{context.Canceled}, h1.Wait())
+		// followers receive the leader's error
+		assert.Equal(t, []error{context.Canceled}, h2.Wait())
+	})
+
+	t.Run("leader cancellation with all policy", func(t *testing.T) {
+		sf := goroutiner.NewSingleflight(goroutiner.SingleflightCancelWithAll)
+		mw := goroutiner.MwSingleflight(sf, fnKey)
+
+		var executions int64
+		release := make(chan struct{})
+
+		leaderCtx, cancelLeader := context.WithCancel(ctx)
+		defer cancelLeader()
+		followerCtx, cancelFollower := context.WithCancel(ctx)
+		defer cancelFollower()
+
+		var cancelledErr error
+		executionDone := make(chan struct{})
+
+		h1 := goroutiner.New().Batch(leaderCtx).Add(func(ctx context.Context) error {
+			defer close(executionDone)
+			atomic.AddInt64(&executions, 1)
+			select {
+			case <-ctx.Done():
+				cancelledErr = ctx.Err()
+				return ctx.Err()
+			case <-release:
+				return nil
+			}
+		}, mw).Start()
+		for atomic.LoadInt64(&executions) != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		h2 := goroutiner.New().Batch(followerCtx).Add(g, mw).Start()
+		waitShared(sf, 1)
+
+		// the leader stops waiting, but the execution goes on for the follower
+		cancelLeader()
+		assert.Equal(t, []error{context.Canceled}, h1.Wait())
+
+		select {
+		case <-executionDone:
+			t.Fatal("execution must not be cancelled, while the follower is waiting")
+		case <-time.After(5 * time.Millisecond):
+		}
+
+		// the execution is cancelled, once all callers are gone
+		cancelFollower()
+		assert.Equal(t, []error{context.Canceled}, h2.Wait())
+
+		<-executionDone
+		assert.Equal(t, context.Canceled, cancelledErr)
+		close(release)
+	})
+
+	t.Run("cancelled execution is not joined with all policy", func(t *testing.T) {
+		sf := goroutiner.NewSingleflight(goroutiner.SingleflightCancelWithAll)
+		mw := goroutiner.MwSingleflight(sf, fnKey)
+
+		var executions int64
+		cleanup := make(chan struct{})
+		defer close(cleanup)
+
+		leaderCtx, cancelLeader := context.WithCancel(ctx)
+		defer cancelLeader()
+
+		h1 := goroutiner.New().Batch(leaderCtx).Add(func(ctx context.Context) error {
+			atomic.AddInt64(&executions, 1)
+			<-ctx.Done()
+			// the cancelled execution takes time to return
+			<-cleanup
+			return ctx.Err()
+		}, mw).Start()
+		for atomic.LoadInt64(&executions) != 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		cancelLeader()
+		assert.Equal(t, []error{context.Canceled}, h1.Wait())
+
+		// the new caller starts a new execution instead of joining the cancelled one
+		assert.Equal(t, []error{nil}, goroutiner.New().Batch(ctx).Add(g, mw).Wait())
+		assert.Equal(t, goroutiner.SingleflightStats{Executions: 2}, sf.Stats())
+	})
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_Mw_Timeout(t *testing.T) {
+	ctx := context.TODO()
+	policies := []goroutiner.TimeoutPolicy{goroutiner.TimeoutWait, goroutiner.TimeoutAbandon}
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.MwTimeout(time.Second, goroutiner.TimeoutWait)
+			goroutiner.MwTimeout(time.Second, goroutiner.TimeoutAbandon)
+		})
+		assert.Panics(t, func() { goroutiner.MwTimeout(0, goroutiner.TimeoutWait) })
+		assert.Panics(t, func() { goroutiner.MwTimeout(-1, goroutiner.TimeoutWait) })
+		assert.Panics(t, func() { goroutiner.MwTimeout(time.Second, goroutiner.TimeoutPolicy(100)) })
+	})
+
+	t.Run("in time", func(t *testing.T) {
+		for _, policy := range policies {
+			errs := goroutiner.New().Batch(ctx).
+				Add(func(ctx context.Context) error { return nil }, goroutiner.MwTimeout(time.Second, policy)).
+				Add(func(ctx context.Context) error { return fmt.Errorf("err") }, goroutiner.MwTimeout(time.Second, policy)).
+				Wait()
+
+			assert.Equal(t, []error{nil, fmt.Errorf("err")}, errs, "policy %d", policy)
+		}
+	})
+
+	t.Run("context-aware goroutine", func(t *testing.T) {
+		for _, policy := range policies {
+			err := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}, goroutiner.MwTimeout(5*time.Millisecond, policy)).Wait()[0]
+
+			assert.ErrorIs(t, err, goroutiner.ErrTimeout, "policy %d", policy)
+
+			var timeoutErr *goroutiner.TimeoutError
+			require.True(t, errors.As(err, &timeoutErr), "policy %d", policy)
+			assert.Equal(t, 5*time.Millisecond, timeoutErr.Timeout, "policy %d", policy)
+		}
+	})
+
+	t.Run("context-ignoring goroutine", func(t *testing.T) {
+		const sleep = 50 * time.Millisecond
+
+		g := func(ctx context.Context) error {
+			time.Sleep(sleep)
+			return nil
+		}
+
+		// wait
+		start := time.Now()
+		err := goroutiner.New().Batch(ctx).Add(g, goroutiner.MwTimeout(5*time.Millisecond, goroutiner.TimeoutWait)).Wait()[0]
+		assert.ErrorIs(t, err, goroutiner.ErrTimeout)
+		assert.GreaterOrEqual(t, time.Since(start), sleep)
+
+		// abandon
+		start = time.Now()
+		err = goroutiner.New().Batch(ctx).Add(g, goroutiner.MwTimeout(5*time.Millisecond, goroutiner.TimeoutAbandon)).Wait()[0]
+		assert.ErrorIs(t, err, goroutiner.ErrTimeout)
+		assert.Less(t, time.Since(start), sleep)
+	})
+
+	t.Run("parent context cancellation is not a timeout", func(t *testing.T) {
+		for _, policy := range policies {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+
+			err := goroutiner.New().Batch(ctx).Add(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}, goroutiner.MwTimeout(time.Second, policy)).Wait()[0]
+
+			cancel()
+
+			assert.NotErrorIs(t, err, goroutiner.ErrTimeout, "policy %d", policy)
+			assert.ErrorIs(t, err, context.DeadlineExceeded, "policy %d", policy)
+		}
+	})
+
+	t.Run("panics are propagated", func(t *testing.T) {
+		mwPanicToError := goroutiner.MwPanicToError(func(panicValue any, debugStack []byte, ctx context.Context) error {
+			return fmt.Errorf("%v", panicValue)
+		})
+
+		for _, policy := range policies {
+			err := goroutiner.New(mwPanicToError).Batch(ctx).Add(func(ctx context.Context) error {
+				panic("timeout panic")
+			}, goroutiner.MwTimeout(time.Second, policy)).Wait()[0]
+
+			assert.EqualError(t, err, "timeout panic", "policy %d", policy)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Pool(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Cfg = goroutiner.PoolConfig
+
+	ctx := context.TODO()
+	mw := func(g G) G { return g }
+	g := func(ctx context.Context) error { return nil }
+
+	// waitStats waits until the `fnCheck` is satisfied by the pool stats.
+	waitStats := func(p *goroutiner.Pool, fnCheck func(s goroutiner.PoolStats) bool) bool {
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if fnCheck(p.Stats()) {
+				return true
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return false
+	}
+
+	t.Run("panic arguments", func(t *testing.T) {
+		grt := goroutiner.New()
+
+		assert.NotPanics(t, func() {
+			grt.Pool(Cfg{Workers: 1})
+			grt.Pool(Cfg{Workers: 2, MinWorkers: 2, QueueSize: 1, IdleTimeout: time.Second}, mw)
+			_, _ = grt.Pool(Cfg{Workers: 1}).Submit(ctx, g, mw)
+		})
+		assert.Panics(t, func() { grt.Pool(Cfg{}) })
+		assert.Panics(t, func() { grt.Pool(Cfg{Workers: 1, MinWorkers: -1}) })
+		assert.Panics(t, func() { grt.Pool(Cfg{Workers: 1, MinWorkers: 2}) })
+		assert.Panics(t, func() { grt.Pool(Cfg{Workers: 1, QueueSize: -1}) })
+		assert.Panics(t, func() { grt.Pool(Cfg{Workers: 1, IdleTimeout: -1}) })
+		assert.Panics(t, func() { grt.Pool(Cfg{Workers: 1}, nil) })
+
+		p := grt.Pool(Cfg{Workers: 1})
+		assert.Panics(t, func() { _, _ = p.Submit(nil, g) })
+		assert.Panics(t, func() { _, _ = p.Submit(ctx, nil) })
+		assert.Panics(t, func() { _, _ = p.Submit(ctx, g, nil) })
+		assert.Panics(t, func() { p.Resize(0) })
+	})
+
+	t.Run("results and counters", func(t *testing.T) {
+		p := goroutiner.New().Pool(Cfg{Workers: 2})
+		errFail := errors.New("fail")
+
+		errChs := make([]<-chan error, 0)
+		for i := 0; i < 10; i++ {
+			i := i
+			errCh, err := p.Submit(ctx, func(ctx context.Context) error {
+				if i%2 == 1 {
+					return errFail
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			errChs = append(errChs, errCh)
+		}
+
+		for i, errCh := range errChs {
+			if i%2 == 1 {
+				assert.Equal(t, errFail, <-errCh)
+			} else {
+				assert.NoError(t, <-errCh)
+			}
+		}
+
+		assert.NoError(t, p.Shutdown(ctx))
+
+		stats := p.Stats()
+		assert.Equal(t, uint64(10), stats.Completed)
+		assert.Equal(t, uint64(5), stats.Failed)
+		assert.Equal(t, 0, stats.Workers)
+		assert.LessOrEqual(t, stats.Queued+stats.Running, 0)
+	})
+
+	t.Run("workers limit and reuse", func(t *testing.T) {
+		p := goroutiner.New().Pool(Cfg{Workers: 3})
+
+		var running, maxRunning int64
+		for i := 0; i < 30; i++ {
+			_, _ = p.Submit(ctx, func(ctx context.Context) error {
+				cur := atomic.AddInt64(&running, 1)
+				for {
+					prev := atomic.LoadInt64(&maxRunning)
+					if cur <= prev || atomic.CompareAndSwapInt64(&maxRunning, prev, cur) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt64(&running, -1)
+				return nil
+			})
+			assert.LessOrEqual(t, p.Stats().Workers, 3)
+		}
+
+		assert.NoError(t, p.Shutdown(ctx))
+		assert.LessOrEqual(t, maxRunning, int64(3))
+		assert.Equal(t, uint64(30), p.Stats().Completed)
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		var actual string
+
+		mMw := func(name string) goroutiner.Middleware {
+			return func(g G) G {
+				return func(ctx context.Context) error {
+					actual += name + "-"
+					return g(ctx)
+				}
+			}
+		}
+
+		p := goroutiner.New(mMw("g")).Pool(Cfg{Workers: 1}, mMw("p"))
+		errCh, _ := p.Submit(ctx, g, mMw("i"))
+		<-errCh
+
+		assert.Equal(t, "g-p-i-", actual)
+	})
+
+	t.Run("bounded queue: backpressure and rejection", func(t *testing.T) {
+		p := goroutiner.New().Pool(Cfg{Workers: 1, QueueSize: 1})
+
+		release := make(chan struct{})
+		blocking := func(ctx context.Context) error {
+			<-release
+			return nil
+		}
+
+		_, err := p.Submit(ctx, blocking)
+		require.NoError(t, err)
+		require.True(t, waitStats(p, func(s goroutiner.PoolStats) bool { return s.Running == 1 }))
+
+		_, err = p.Submit(ctx, blocking) // queued
+		require.NoError(t, err)
+
+		_, err = p.TrySubmit(ctx, blocking)
+		assert.Equal(t, goroutiner.ErrPoolFull, err)
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		_, err = p.Submit(timeoutCtx, blocking)
+		cancel()
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		// waits for a free place
+		submitted := make(chan error)
+		go func() {
+			_, err := p.Submit(ctx, g)
+			submitted <- err
+		}()
+
+		close(release)
+		assert.NoError(t, <-submitted)
+		assert.NoError(t, p.Shutdown(ctx))
+		assert.Equal(t, uint64(3), p.Stats().Completed)
+	})
+
+	t.Run("cancelled context before start", func(t *testing.T) {
+		p := goroutiner.New().Pool(Cfg{Workers: 1})
+
+		release := make(chan struct{})
+		_, _ = p.Submit(ctx, func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		executed := false
+		errCh, err := p.Submit(cancelledCtx, func(ctx context.Context) error {
+			executed = true
+			return nil
+		})
+		require.NoError(t, err)
+		cancel()
+		close(release)
+
+		assert.Equal(t, context.Canceled, <-errCh)
+		assert.False(t, executed)
+	})
+
+	t.Run("resize", func(t *testing.T) {
+		p := goroutiner.New().Pool(Cfg{Workers: 1})
+
+		release := make(chan struct{})
+		blocking := func(ctx context.Context) error {
+			<-release
+			return nil
+		}
+
+		for i := 0; i < 4; i++ {
+			_, _ = p.Submit(ctx, blocking)
+		}
+		require.True(t, waitStats(p, func(s goroutiner.PoolStats) bool { return s.Running == 1 && s.Queued == 3 }))
+
+		p.Resize(4)
+		assert.True(t, waitStats(p, func(s goroutiner.PoolStats) bool { return s.Running == 4 && s.Workers == 4 }))
+
+		p.Resize(2)
+		close(release)
+		assert.True(t, waitStats(p, func(s goroutiner.PoolStats) bool { return s.Workers == 2 }))
+
+		assert.NoError(t, p.Shutdown(ctx))
+	})
+
+	t.Run("idle workers reaping", func(t *testing.T) {
+		p := goroutiner.New().Pool(Cfg{Workers: 3, MinWorkers: 1, IdleTimeout: 5 * time.Millisecond})
+		assert.Equal(t, 1, p.Stats().Workers)
+
+		release := make(chan struct{})
+		for i := 0; i < 3; i++ {
+			_, _ = p.Submit(ctx, func(ctx context.Context) error {
+				<-release
+				return nil
+			})
+		}
+		require.True(t, waitStats(p, func(s goroutiner.PoolStats) bool { return s.Workers == 3 }))
+
+		close(release)
+		assert.True(t, waitStats(p, func(s goroutiner.PoolStats) bool { return s.Workers == 1 && s.Idle == 1 }))
+
+		assert.NoError(t, p.Shutdown(ctx))
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		p := goroutiner.New().Pool(Cfg{Workers: 1})
+
+		release := make(chan struct{})
+		var completed int64
+		for i := 0; i < 3; i++ {
+			_, _ = p.Submit(ctx, func(ctx context.Context) error {
+				<-release
+				atomic.AddInt64(&completed, 1)
+				return nil
+			})
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		assert.Equal(t, context.DeadlineExceeded, p.Shutdown(timeoutCtx))
+		cancel()
+
+		_, err := p.Submit(ctx, g)
+		assert.Equal(t, goroutiner.ErrPoolClosed, err)
+
+		// queue is drained
+		close(release)
+		assert.NoError(t, p.Shutdown(ctx))
+		assert.Equal(t, int64(3), atomic.LoadInt64(&completed))
+	})
+}
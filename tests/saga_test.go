@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Saga(t *testing.T) {
+	type G = goroutiner.Goroutine
+
+	ctx := context.TODO()
+	mw := func(g G) G { return g }
+	g := func(ctx context.Context) error { return nil }
+
+	// journal records executed steps and compensations in order.
+	type journal struct {
+		mu      sync.Mutex
+		entries []string
+	}
+
+	record := func(j *journal, entry string, err error) G {
+		return func(ctx context.Context) error {
+			j.mu.Lock()
+			j.entries = append(j.entries, entry)
+			j.mu.Unlock()
+			return err
+		}
+	}
+
+	t.Run("panic arguments", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			goroutiner.New().Saga(ctx).Step(g, nil)
+			goroutiner.New().Saga(ctx, mw).Step(g, g, mw).Parallel(g, nil, mw).CompensationMiddleware(mw).Run()
+		})
+		assert.Panics(t, func() { goroutiner.New().Saga(nil) })
+		assert.Panics(t, func() { goroutiner.New().Saga(ctx, nil) })
+		assert.Panics(t, func() { goroutiner.New().Saga(ctx).Step(nil, g) })
+		assert.Panics(t, func() { goroutiner.New().Saga(ctx).Step(g, g, nil) })
+		assert.Panics(t, func() { goroutiner.New().Saga(ctx).Parallel(g, g) })
+		assert.Panics(t, func() { goroutiner.New().Saga(ctx).Step(g, g).Parallel(nil, g) })
+		assert.Panics(t, func() { goroutiner.New().Saga(ctx).CompensationMiddleware(nil) })
+		assert.Panics(t, func() { goroutiner.New().Saga(ctx).Run() })
+	})
+
+	t.Run("all steps completed", func(t *testing.T) {
+		j := new(journal)
+
+		err := goroutiner.New().Saga(ctx).
+			Step(record(j, "reserve", nil), record(j, "unreserve", nil)).
+			Step(record(j, "charge", nil), record(j, "refund", nil)).
+			Run()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"reserve", "charge"}, j.entries)
+	})
+
+	t.Run("completed steps are compensated in reverse order", func(t *testing.T) {
+		j := new(journal)
+		errShip := errors.New("ship failed")
+
+		err := goroutiner.New().Saga(ctx).
+			Step(record(j, "reserve", nil), record(j, "unreserve", nil)).
+			Step(record(j, "notify", nil), nil).
+			Step(record(j, "charge", nil), record(j, "refund", nil)).
+			Step(record(j, "ship", errShip), record(j, "unship", nil)).
+			Step(record(j, "close", nil), record(j, "reopen", nil)).
+			Run()
+
+		assert.Equal(t, []string{"reserve", "notify", "charge", "ship", "refund", "unreserve"}, j.entries)
+
+		assert.ErrorIs(t, err, errShip)
+		assert.NotErrorIs(t, err, goroutiner.ErrCompensationFailed)
+
+		var sagaErr *goroutiner.SagaError
+		require.True(t, errors.As(err, &sagaErr))
+		assert.Equal(t, []error{nil, nil, nil, errShip, goroutiner.ErrSkipped}, sagaErr.StepErrors)
+		assert.Equal(t, make([]error, 5), sagaErr.CompensationErrors)
+		assert.Equal(t, errShip, errors.Unwrap(err))
+	})
+
+	t.Run("parallel groups", func(t *testing.T) {
+		j := new(journal)
+		errCharge := errors.New("charge failed")
+		errRefund := errors.New("refund failed")
+
+		// blocks, unless both steps are running concurrently.
+		barrier := new(sync.WaitGroup)
+		barrier.Add(2)
+		waitBoth := func(entry string, err error) G {
+			return func(ctx context.Context) error {
+				barrier.Done()
+				barrier.Wait()
+				return record(j, entry, err)(ctx)
+			}
+		}
+
+		err := goroutiner.New().Saga(ctx).
+			Step(record(j, "reserve", nil), record(j, "unreserve", nil)).
+			Parallel(record(j, "book", nil), record(j, "unbook", errRefund)).
+			Step(waitBoth("charge", errCharge), record(j, "refund", nil)).
+			Parallel(waitBoth("points", nil), record(j, "unpoints", nil)).
+			Step(record(j, "ship", nil), record(j, "unship", nil)).
+			Run()
+
+		require.Len(t, j.entries, 7)
+		assert.ElementsMatch(t, []string{"reserve", "book"}, j.entries[0:2])
+		assert.ElementsMatch(t, []string{"charge", "points"}, j.entries[2:4])
+		assert.Equal(t, "unpoints", j.entries[4])
+		assert.ElementsMatch(t, []string{"unreserve", "unbook"}, j.entries[5:7])
+
+		assert.ErrorIs(t, err, errCharge)
+		assert.ErrorIs(t, err, errRefund)
+		assert.ErrorIs(t, err, goroutiner.ErrCompensationFailed)
+
+		var sagaErr *goroutiner.SagaError
+		require.True(t, errors.As(err, &sagaErr))
+		assert.Equal(t, []error{nil, nil, errCharge, nil, goroutiner.ErrSkipped}, sagaErr.StepErrors)
+		assert.Equal(t, []error{nil, errRefund, nil, nil, nil}, sagaErr.CompensationErrors)
+		assert.EqualError(
+			t,
+			err,
+			"saga failed: 1 step(s) failed, 2 step(s) compensated\n  step #2: charge failed\n  compensation #1: refund failed",
+		)
+	})
+
+	t.Run("compensation middleware and context", func(t *testing.T) {
+		type ctxKey struct{}
+
+		ctx, cancel := context.WithCancel(context.WithValue(ctx, ctxKey{}, "value"))
+		defer cancel()
+
+		appliedMws := make([]string, 0)
+		mMw := func(name string) goroutiner.Middleware {
+			return func(g G) G {
+				return func(ctx context.Context) error {
+					appliedMws = append(appliedMws, name)
+					return g(ctx)
+				}
+			}
+		}
+
+		err := goroutiner.New(mMw("global")).Saga(ctx, mMw("saga")).
+			CompensationMiddleware(mMw("compensation")).
+			Step(func(ctx context.Context) error {
+				return nil
+			}, func(ctx context.Context) error {
+				assert.NoError(t, ctx.Err())
+				assert.Equal(t, "value", ctx.Value(ctxKey{}))
+				return nil
+			}, mMw("step")).
+			Step(func(ctx context.Context) error {
+				cancel()
+				return ctx.Err()
+			}, nil).
+			Step(g, g).
+			Run()
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"global", "saga", "step", "global", "saga", "global", "compensation"}, appliedMws)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		compensated := false
+
+		err := goroutiner.New().Saga(ctx).Timeout(10*time.Millisecond).
+			Step(g, func(ctx context.Context) error {
+				compensated = true
+				return nil
+			}).
+			Step(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}, nil).
+			Run()
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.True(t, compensated)
+	})
+
+	t.Run("compensation info", func(t *testing.T) {
+		var stepInfo goroutiner.GoroutineInfo
+		compensationInfos := make([]goroutiner.GoroutineInfo, 0)
+
+		mwInfo := func(g G) G {
+			return func(ctx context.Context) error {
+				info, ok := goroutiner.InfoFromContext(ctx)
+				require.True(t, ok)
+				compensationInfos = append(compensationInfos, info)
+				return g(ctx)
+			}
+		}
+
+		err := goroutiner.New().Saga(ctx).CompensationMiddleware(mwInfo).
+			Step(func(ctx context.Context) error {
+				stepInfo, _ = goroutiner.InfoFromContext(ctx)
+				return nil
+			}, g).
+			Step(g, g).
+			Step(func(ctx context.Context) error { return errors.New("fail") }, nil).
+			Run()
+
+		require.Error(t, err)
+		require.Len(t, compensationInfos, 2)
+		for j, index := range []int{1, 0} {
+			assert.Equal(t, index, compensationInfos[j].Index)
+			assert.Equal(t, stepInfo.BatchID, compensationInfos[j].BatchID)
+			assert.Equal(t, "SagaCompensation", compensationInfos[j].Strategy)
+		}
+	})
+
+	t.Run("propagate panics after compensation", func(t *testing.T) {
+		compensated := false
+
+		assert.PanicsWithValue(t, "step panicked", func() {
+			defer func() {
+				if pv := recover(); pv != nil {
+					panic(pv.(*goroutiner.GoroutinePanic).Value)
+				}
+			}()
+
+			_ = goroutiner.New().Saga(ctx).PropagatePanics().
+				Step(g, func(ctx context.Context) error {
+					compensated = true
+					return nil
+				}).
+				Step(func(ctx context.Context) error {
+					panic("step panicked")
+				}, nil).
+				Run()
+		})
+
+		assert.True(t, compensated)
+	})
+
+	t.Run("propagate compensation panics after compensation", func(t *testing.T) {
+		errFail := errors.New("fail")
+		compensated := false
+
+		assert.PanicsWithValue(t, "compensation panicked #1", func() {
+			defer func() {
+				if pv := recover(); pv != nil {
+					gp := pv.(*goroutiner.GoroutinePanic)
+					panic(fmt.Sprintf("%v #%d", gp.Value, gp.Index))
+				}
+			}()
+
+			_ = goroutiner.New().Saga(ctx).PropagatePanics().
+				Step(g, func(ctx context.Context) error {
+					compensated = true
+					return nil
+				}).
+				Step(g, func(ctx context.Context) error {
+					panic("compensation panicked")
+				}).
+				Step(func(ctx context.Context) error {
+					return errFail
+				}, nil).
+				Run()
+		})
+
+		// the panicked compensation does not interrupt the rollback
+		assert.True(t, compensated)
+	})
+}
@@ -0,0 +1,319 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// supervisorTestContext calls hooks on each Err and Done call -- allows to cancel the context at the particular moment.
+type supervisorTestContext struct {
+	context.Context
+	onErr  func()
+	onDone func()
+}
+
+func (c *supervisorTestContext) Err() error {
+	c.onErr()
+	return c.Context.Err()
+}
+
+func (c *supervisorTestContext) Done() <-chan struct{} {
+	c.onDone()
+	return c.Context.Done()
+}
+
+func Test_Supervisor(t *testing.T) {
+	type G = goroutiner.Goroutine
+	type Cfg = goroutiner.SupervisorConfig
+
+	ctx := context.TODO()
+	mw := func(g G) G { return g }
+	g := func(ctx context.Context) error { return nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		grt := goroutiner.New()
+
+		assert.NotPanics(t, func() {
+			grt.Supervisor(Cfg{})
+			grt.Supervisor(Cfg{Strategy: goroutiner.OneForAll}, mw)
+			grt.Supervisor(Cfg{Strategy: goroutiner.RestForOne, MaxRestarts: 1, Period: time.Second})
+			grt.Supervisor(Cfg{}).Add("a", g).Add("b", g, mw)
+		})
+		assert.Panics(t, func() { grt.Supervisor(Cfg{Strategy: goroutiner.RestartStrategy(100)}) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{MaxRestarts: -1}) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{MaxRestarts: 1}) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{InitialBackoff: -1}) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{MaxBackoff: -1}) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{BackoffResetAfter: -1}) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{}, nil) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{}).Add("a", nil) })
+		assert.Panics(t, func() { grt.Supervisor(Cfg{}).Add("a", g, nil) })
+		assert.Panics(t, func() { _, _ = grt.Supervisor(Cfg{}).Run(ctx) })
+	})
+
+	// recorder counts starts of children and allows to fail them on demand.
+	type Recorder struct {
+		mu     sync.Mutex
+		starts map[string]int
+		fail   map[string]chan error
+	}
+
+	newRecorder := func(names ...string) *Recorder {
+		r := &Recorder{starts: make(map[string]int), fail: make(map[string]chan error)}
+		for _, name := range names {
+			r.fail[name] = make(chan error, 10)
+		}
+		return r
+	}
+
+	mChild := func(r *Recorder, name string) G {
+		return func(ctx context.Context) error {
+			r.mu.Lock()
+			r.starts[name]++
+			r.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-r.fail[name]:
+				if err == nil {
+					panic("child panic")
+				}
+				return err
+			}
+		}
+	}
+
+	starts := func(r *Recorder) map[string]int {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		res := make(map[string]int)
+		for k, v := range r.starts {
+			res[k] = v
+		}
+		return res
+	}
+
+	errFail := errors.New("fail")
+
+	for _, tCase := range []struct {
+		strategy goroutiner.RestartStrategy
+		expected map[string]int
+	}{
+		{goroutiner.OneForOne, map[string]int{"a": 1, "b": 2, "c": 1}},
+		{goroutiner.OneForAll, map[string]int{"a": 2, "b": 2, "c": 2}},
+		{goroutiner.RestForOne, map[string]int{"a": 1, "b": 2, "c": 2}},
+	} {
+		t.Run(fmt.Sprintf("strategy %d", tCase.strategy), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			r := newRecorder("a", "b", "c")
+
+			restarted := make(chan struct{}, 1)
+			s := goroutiner.New().
+				Supervisor(Cfg{
+					Strategy: tCase.strategy,
+					FnOnRestart: func(name string, err error, ctx context.Context) {
+						assert.Equal(t, "b", name)
+						assert.Equal(t, errFail, err)
+						restarted <- struct{}{}
+					},
+				}).
+				Add("a", mChild(r, "a")).
+				Add("b", mChild(r, "b")).
+				Add("c", mChild(r, "c"))
+
+			go func() {
+				r.fail["b"] <- errFail
+				<-restarted
+				// waits for restarted children
+				for {
+					if cur := starts(r); cur["b"] == 2 && cur["a"] == tCase.expected["a"] && cur["c"] == tCase.expected["c"] {
+						break
+					}
+					time.Sleep(time.Millisecond)
+				}
+				cancel()
+			}()
+
+			exits, err := s.Run(ctx)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tCase.expected, starts(r))
+			require.Len(t, exits, 3)
+			for i, name := range []string{"a", "b", "c"} {
+				assert.Equal(t, name, exits[i].Name)
+				assert.Equal(t, tCase.expected[name]-1, exits[i].Restarts)
+				assert.Equal(t, context.Canceled, exits[i].Err)
+			}
+		})
+	}
+
+	t.Run("panics are restarted", func(t *testing.T) {
+		r := newRecorder("a")
+		r.fail["a"] <- nil // panic
+		r.fail["a"] <- errFail
+
+		exits, err := goroutiner.New().
+			Supervisor(Cfg{MaxRestarts: 1, Period: time.Second}).
+			Add("a", mChild(r, "a")).
+			Run(ctx)
+
+		// the first restart is allowed, the second one exceeds the intensity
+		assert.ErrorIs(t, err, goroutiner.ErrRestartIntensity)
+		assert.Equal(t, 2, starts(r)["a"])
+		assert.Equal(t, 1, exits[0].Restarts)
+		assert.Equal(t, errFail, exits[0].Err)
+	})
+
+	t.Run("completed children are not restarted", func(t *testing.T) {
+		exits, err := goroutiner.New().
+			Supervisor(Cfg{Strategy: goroutiner.OneForAll}).
+			Add("a", g).
+			Add("b", g).
+			Run(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []goroutiner.ChildExit{{Name: "a"}, {Name: "b"}}, exits)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		attempts := 0
+		start := time.Now()
+
+		exits, err := goroutiner.New().
+			Supervisor(Cfg{MaxRestarts: 3, Period: time.Second, InitialBackoff: 5 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}).
+			Add("a", func(ctx context.Context) error {
+				attempts++
+				if attempts <= 3 {
+					return errFail
+				}
+				return nil
+			}).
+			Run(ctx)
+
+		// 5ms + 10ms + 10ms (capped)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, exits[0].Restarts)
+		assert.Nil(t, exits[0].Err)
+		assert.GreaterOrEqual(t, time.Since(start), 25*time.Millisecond)
+	})
+
+	t.Run("backoff reset", func(t *testing.T) {
+		starts := make([]time.Time, 0)
+
+		exits, err := goroutiner.New().
+			Supervisor(Cfg{InitialBackoff: 20 * time.Millisecond, BackoffResetAfter: 30 * time.Millisecond}).
+			Add("a", func(ctx context.Context) error {
+				starts = append(starts, time.Now())
+				switch len(starts) {
+				case 3:
+					// healthy run
+					time.Sleep(35 * time.Millisecond)
+				case 4:
+					return nil
+				}
+				return errFail
+			}).
+			Run(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, exits[0].Restarts)
+		require.Len(t, starts, 4)
+
+		// 20ms + 40ms, then 20ms again (instead of 80ms) after the healthy run
+		assert.GreaterOrEqual(t, starts[2].Sub(starts[1]), 40*time.Millisecond)
+		assert.Less(t, starts[3].Sub(starts[2]), 35*time.Millisecond+60*time.Millisecond)
+	})
+
+	t.Run("context cancellation stops children", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+		defer cancel()
+
+		r := newRecorder("a")
+
+		exits, err := goroutiner.New().
+			Supervisor(Cfg{}).
+			Add("a", mChild(r, "a")).
+			Run(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, context.Canceled, exits[0].Err)
+	})
+
+	t.Run("context cancellation right after restart", func(t *testing.T) {
+		// exits of stopped children must not block the supervisor, when the context is done right after the restart
+		for k := 0; k < 20; k++ {
+			ctx, cancel := context.WithCancel(ctx)
+
+			// the context is checked after stopping children, and is cancelled, once restarted children are started.
+			restarting, stopped := false, false
+			hookedCtx := &supervisorTestContext{
+				Context: ctx,
+				onErr: func() {
+					stopped = restarting
+				},
+				onDone: func() {
+					if stopped {
+						cancel()
+					}
+				},
+			}
+
+			names := []string{"a", "b", "c", "d", "e"}
+			r := newRecorder(names...)
+			r.fail["a"] <- errFail
+
+			s := goroutiner.New().Supervisor(Cfg{
+				Strategy: goroutiner.OneForAll,
+				FnOnRestart: func(name string, err error, ctx context.Context) {
+					restarting = true
+				},
+			})
+			for _, name := range names {
+				s.Add(name, mChild(r, name))
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				exits, err := s.Run(hookedCtx)
+				assert.NoError(t, err)
+				assert.Len(t, exits, len(names))
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("supervisor hangs")
+			}
+
+			cancel()
+		}
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		var actual string
+
+		mMw := func(name string) goroutiner.Middleware {
+			return func(g G) G {
+				return func(ctx context.Context) error {
+					actual += name + "-"
+					return g(ctx)
+				}
+			}
+		}
+
+		_, _ = goroutiner.New(mMw("g")).Supervisor(Cfg{}, mMw("s")).Add("a", g, mMw("i")).Run(ctx)
+
+		assert.Equal(t, "g-s-i-", actual)
+	})
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goroutiner "github.com/selyukovn/go-routiner"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+)
+
+func Test_TypedBatch(t *testing.T) {
+	type TG = goroutiner.TypedGoroutine[int]
+	type G = goroutiner.Goroutine
+	type Mw = goroutiner.Middleware
+
+	ctx := context.TODO()
+	mw := func(g G) G { return g }
+	tg := func(ctx context.Context) (int, error) { return 1, nil }
+
+	t.Run("panic arguments", func(t *testing.T) {
+		grt := goroutiner.New()
+
+		assert.NotPanics(t, func() {
+			goroutiner.NewTypedBatch[int](grt, ctx)
+			goroutiner.NewTypedBatch[int](grt, ctx, mw)
+			goroutiner.NewTypedBatch[int](grt, ctx).Add(tg).Add(tg, mw)
+			goroutiner.NewTypedBatch[int](grt, ctx).AddRange(2, func(i int) (TG, []Mw) { return tg, []Mw{mw} })
+		})
+		assert.Panics(t, func() { goroutiner.NewTypedBatch[int](nil, ctx) })
+		assert.Panics(t, func() { goroutiner.NewTypedBatch[int](grt, nil) })
+		assert.Panics(t, func() { goroutiner.NewTypedBatch[int](grt, ctx, nil) })
+		assert.Panics(t, func() { goroutiner.NewTypedBatch[int](grt, ctx).Add(nil) })
+		assert.Panics(t, func() { goroutiner.NewTypedBatch[int](grt, ctx).Add(tg, nil) })
+		assert.Panics(t, func() { goroutiner.NewTypedBatch[int](grt, ctx).AddRange(0, func(i int) (TG, []Mw) { return tg, nil }) })
+		assert.PanicsWithValue(t, "`n` must be greater than zero", func() {
+			goroutiner.NewTypedBatch[int](grt, ctx).AddRange(-1, func(i int) (TG, []Mw) { return tg, nil })
+		})
+		assert.Panics(t, func() { goroutiner.NewTypedBatch[int](grt, ctx).AddRange(1, nil) })
+		assert.Panics(t, func() {
+			goroutiner.NewTypedBatch[int](grt, ctx).AddRange(1, func(i int) (TG, []Mw) { return nil, nil })
+		})
+
+		// "all or nothing" -- no goroutines are added after the panic.
+		tb := goroutiner.NewTypedBatch[int](grt, ctx)
+		assert.Panics(t, func() {
+			tb.AddRange(3, func(i int) (TG, []Mw) {
+				if i < 2 {
+					return tg, nil
+				}
+				return tg, []Mw{nil}
+			})
+		})
+		assert.Panics(t, func() { _, _ = tb.Wait() })
+		assert.Panics(t, func() { _, _ = tb.CancelOnError() })
+		assert.Panics(t, func() { _ = tb.Async() })
+		assert.Panics(t, func() { _ = tb.AsyncBs(1) })
+	})
+
+	// mG returns `i * 10` as a value, and an error if `i` is odd.
+	mG := func(i int) TG {
+		return func(ctx context.Context) (int, error) {
+			if i%2 == 1 {
+				return i * 10, fmt.Errorf("error #%d", i)
+			}
+			return i * 10, nil
+		}
+	}
+
+	t.Run("Wait", func(t *testing.T) {
+		values, errs := goroutiner.NewTypedBatch[int](goroutiner.New(), ctx).
+			AddRange(4, func(i int) (TG, []Mw) { return mG(i), nil }).
+			Wait()
+
+		assert.Equal(t, []int{0, 10, 20, 30}, values)
+		assert.Equal(t, []error{nil, fmt.Errorf("error #1"), nil, fmt.Errorf("error #3")}, errs)
+	})
+
+	t.Run("CancelOnError", func(t *testing.T) {
+		values, err := goroutiner.NewTypedBatch[int](goroutiner.New(), ctx).
+			Add(mG(0)).Add(mG(2)).Add(mG(4)).
+			CancelOnError()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 20, 40}, values)
+
+		values, err = goroutiner.NewTypedBatch[int](goroutiner.New(), ctx).
+			Limit(1).
+			Add(mG(0)).Add(mG(1)).Add(mG(2)).
+			CancelOnError()
+
+		assert.EqualError(t, err, "error #1")
+		assert.Equal(t, []int{0}, values)
+	})
+
+	t.Run("Async", func(t *testing.T) {
+		tb := goroutiner.NewTypedBatch[int](goroutiner.New(), ctx).
+			AddRange(4, func(i int) (TG, []Mw) { return mG(i), nil })
+
+		for _, resCh := range []<-chan goroutiner.Result[int]{tb.Async(), tb.AsyncBs(0)} {
+			results := make([]goroutiner.Result[int], 0)
+			for res := range resCh {
+				results = append(results, res)
+			}
+			sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+			assert.Equal(t, []goroutiner.Result[int]{
+				{Index: 0, Value: 0, Err: nil},
+				{Index: 1, Value: 10, Err: fmt.Errorf("error #1")},
+				{Index: 2, Value: 20, Err: nil},
+				{Index: 3, Value: 30, Err: fmt.Errorf("error #3")},
+			}, results)
+		}
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		var actual string
+
+		mMw := func(name string) Mw {
+			return func(g G) G {
+				return func(ctx context.Context) error {
+					actual += name + "-"
+					return g(ctx)
+				}
+			}
+		}
+
+		errMw := errors.New("middleware error")
+		mwErr := func(g G) G {
+			return func(ctx context.Context) error {
+				_ = g(ctx)
+				return errMw
+			}
+		}
+
+		values, errs := goroutiner.NewTypedBatch[int](goroutiner.New(mMw("g")), ctx, mMw("s")).
+			Add(func(ctx context.Context) (int, error) {
+				actual += "0"
+				return 5, nil
+			}, mMw("i")).
+			Wait()
+
+		assert.Equal(t, "g-s-i-0", actual)
+		assert.Equal(t, []int{5}, values)
+		assert.Equal(t, []error{nil}, errs)
+
+		// middleware errors make the goroutine failed
+		values, err := goroutiner.NewTypedBatch[int](goroutiner.New(), ctx).Add(mG(0), mwErr).Add(mG(2)).CancelOnError()
+		assert.Equal(t, errMw, err)
+		assert.NotContains(t, values, 0)
+	})
+}
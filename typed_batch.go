@@ -0,0 +1,262 @@
+package goroutiner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Struct
+// ---------------------------------------------------------------------------------------------------------------------
+
+type TypedGoroutine[T any] func(context.Context) (T, error)
+
+// Result is a result of the `Index`-th goroutine of the TypedBatch.
+type Result[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// TypedBatch is a counterpart of the Batch for goroutines returning values.
+//
+// Middleware are the same as for the Batch, so the global, batch and individual middleware are applied
+// in the same order. Since middleware work with Goroutine, they see only the error of the typed goroutine.
+//
+// Not thread-safe, as there is no practical need to make it thread‑safe.
+type TypedBatch[T any] struct {
+	// batch keeps the context, middleware, settings and goroutine configs.
+	// Goroutines of the configs are replaced with the typed ones on each execution.
+	batch *Batch
+	fns   []TypedGoroutine[T]
+}
+
+// typedValues stores values returned by typed goroutines within one execution.
+type typedValues[T any] struct {
+	mu     sync.Mutex
+	values []T
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Create
+// ---------------------------------------------------------------------------------------------------------------------
+
+// NewTypedBatch creates a new typed batch with the given context and optional batch middleware.
+// It is the same as Goroutiner.Batch, but for goroutines returning values.
+//
+// Panics if:
+//   - `g` is nil
+//   - `ctx` is nil
+//   - `mws` contains nil
+func NewTypedBatch[T any](g *Goroutiner, ctx context.Context, mws ...Middleware) *TypedBatch[T] {
+	if g == nil {
+		panic("`g` must not be `nil`")
+	}
+
+	return &TypedBatch[T]{
+		batch: g.Batch(ctx, mws...),
+		fns:   make([]TypedGoroutine[T], 0),
+	}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Actions
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Add -- see Batch.Add.
+func (tb *TypedBatch[T]) Add(fn TypedGoroutine[T], mws ...Middleware) *TypedBatch[T] {
+	if fn == nil {
+		panic("`fn` must not be `nil`")
+	}
+
+	tb.batch.Add(tb.placeholder(), mws...)
+	tb.fns = append(tb.fns, fn)
+
+	return tb
+}
+
+// AddRange -- see Batch.AddRange.
+func (tb *TypedBatch[T]) AddRange(n int, fnProvide func(i int) (TypedGoroutine[T], []Middleware)) *TypedBatch[T] {
+	if n <= 0 {
+		panic("`n` must be greater than zero")
+	}
+
+	if fnProvide == nil {
+		panic("`fnProvide` must not be `nil`")
+	}
+
+	// Batch.AddRange guarantees "all or nothing", so typed goroutines are appended only after it.
+	fns := make([]TypedGoroutine[T], 0, n)
+
+	tb.batch.AddRange(n, func(i int) (Goroutine, []Middleware) {
+		fn, mws := fnProvide(i)
+
+		if fn == nil {
+			panic("`fnProvide` must not provide `nil` goroutines")
+		}
+
+		fns = append(fns, fn)
+
+		return tb.placeholder(), mws
+	})
+
+	tb.fns = append(tb.fns, fns...)
+
+	return tb
+}
+
+// Limit -- see Batch.Limit.
+func (tb *TypedBatch[T]) Limit(n int) *TypedBatch[T] {
+	tb.batch.Limit(n)
+
+	return tb
+}
+
+// Timeout -- see Batch.Timeout.
+func (tb *TypedBatch[T]) Timeout(d time.Duration) *TypedBatch[T] {
+	tb.batch.Timeout(d)
+
+	return tb
+}
+
+// Deadline -- see Batch.Deadline.
+func (tb *TypedBatch[T]) Deadline(t time.Time) *TypedBatch[T] {
+	tb.batch.Deadline(t)
+
+	return tb
+}
+
+// PropagatePanics -- see Batch.PropagatePanics.
+func (tb *TypedBatch[T]) PropagatePanics() *TypedBatch[T] {
+	tb.batch.PropagatePanics()
+
+	return tb
+}
+
+// Executing
+// ---------------------------------------------------------------------------------------------------------------------
+
+func (tb *TypedBatch[T]) placeholder() Goroutine {
+	return func(context.Context) error {
+		panic("placeholder goroutine must be replaced with the typed one before execution")
+	}
+}
+
+// prepare returns a copy of the inner batch, which goroutines store their values into the returned storage.
+func (tb *TypedBatch[T]) prepare() (*Batch, *typedValues[T]) {
+	tv := &typedValues[T]{values: make([]T, len(tb.fns))}
+
+	b := *tb.batch
+	b.goroutineConfigs = make([]*goroutineConfig, len(tb.batch.goroutineConfigs))
+
+	for i, cfg := range tb.batch.goroutineConfigs {
+		typedCfg := *cfg
+		typedCfg.fn = tv.untyped(i, tb.fns[i])
+		b.goroutineConfigs[i] = &typedCfg
+	}
+
+	return &b, tv
+}
+
+func (tv *typedValues[T]) untyped(i int, fn TypedGoroutine[T]) Goroutine {
+	return func(ctx context.Context) error {
+		v, err := fn(ctx)
+
+		tv.mu.Lock()
+		tv.values[i] = v
+		tv.mu.Unlock()
+
+		return err
+	}
+}
+
+func (tv *typedValues[T]) get(i int) T {
+	tv.mu.Lock()
+	defer tv.mu.Unlock()
+
+	return tv.values[i]
+}
+
+// Execution - Wait
+// ---------------------------------------------------------------------------------------------------------------------
+
+// Wait -- see Batch.Wait.
+// Returns slices of values and errors: index `i` matches `i`-th added goroutine.
+// Value of a failed goroutine is the one it returned along with the error.
+func (tb *TypedBatch[T]) Wait() ([]T, []error) {
+	b, tv := tb.prepare()
+
+	errs := b.Wait()
+
+	values := make([]T, len(errs))
+	for i := range values {
+		values[i] = tv.get(i)
+	}
+
+	return values, errs
+}
+
+// Execution - CancelOnError
+// ---------------------------------------------------------------------------------------------------------------------
+
+// CancelOnError -- see Batch.CancelOnError.
+// Returns values of all successfully completed goroutines (in the adding order) and the first non-nil error (if any).
+func (tb *TypedBatch[T]) CancelOnError() ([]T, error) {
+	b, tv := tb.prepare()
+
+	gs := b.prepareGoroutines("CancelOnError")
+	succeeded := make([]bool, len(gs))
+
+	err := b.cancelOnError(gs, func(i int, err error) {
+		succeeded[i] = err == nil
+	})
+
+	values := make([]T, 0, len(gs))
+	for i, ok := range succeeded {
+		if ok {
+			values = append(values, tv.get(i))
+		}
+	}
+
+	return values, err
+}
+
+// Execution - Async
+// ---------------------------------------------------------------------------------------------------------------------
+
+func (tb *TypedBatch[T]) async(b *Batch, tv *typedValues[T], gs []Goroutine, resChBufferSize uint) <-chan Result[T] {
+	resCh := make(chan Result[T], resChBufferSize)
+
+	ctx, cancel := b.executionContext()
+
+	go func(resCh chan<- Result[T], gs []Goroutine, ctx context.Context) {
+		defer close(resCh)
+		defer cancel()
+
+		b.execute(ctx, gs, func(i int, err error) {
+			resCh <- Result[T]{Index: i, Value: tv.get(i), Err: err}
+		})
+	}(resCh, gs, ctx)
+
+	return resCh
+}
+
+// Async -- see Batch.Async.
+// Results are sent to the channel in the completion order, so Result.Index should be used to match goroutines.
+func (tb *TypedBatch[T]) Async() <-chan Result[T] {
+	b, tv := tb.prepare()
+	gs := b.prepareGoroutines("Async")
+
+	return tb.async(b, tv, gs, uint(len(gs)))
+}
+
+// AsyncBs -- see Batch.AsyncBs.
+func (tb *TypedBatch[T]) AsyncBs(resChBufferSize uint) <-chan Result[T] {
+	b, tv := tb.prepare()
+	gs := b.prepareGoroutines("AsyncBs")
+
+	return tb.async(b, tv, gs, resChBufferSize)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------